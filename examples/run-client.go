@@ -44,38 +44,38 @@ func main() {
 	fmt.Println(fmt.Sprintf("GetDataSource - %#v", dataSource))
 
 	// DataSource creation
-	postPayload := redash.DataSource{
-		Name: "My new Redshift data source",
-		Type: "redshift",
-		Options: map[string]interface{}{
-			"host":     "localhost",
-			"port":     5439,
-			"dbname":   "my_database",
-			"user":     "user_name",
-			"password": "S3cuR3PaSsW0rD",
-		},
+	postPayload, err := redash.NewDataSource("My new Redshift data source", &redash.RedshiftOptions{
+		Host:     "localhost",
+		Port:     5439,
+		DBName:   "my_database",
+		User:     "user_name",
+		Password: "S3cuR3PaSsW0rD",
+	})
+	if err != nil {
+		log.Fatal(err)
+		return
 	}
 
-	newDataSource, err := c.CreateDataSource(&postPayload)
+	newDataSource, err := c.CreateDataSource(postPayload)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 	fmt.Println(fmt.Sprintf("CreateDataSource - %#v", newDataSource))
 
-	postPayload = redash.DataSource{
-		Name: "My new Redshift data source v2",
-		Type: "redshift",
-		Options: map[string]interface{}{
-			"host":     "localhost",
-			"port":     5439,
-			"dbname":   "my_database",
-			"user":     "user_name",
-			"password": "S3cuR3PaSsW0rD",
-		},
+	postPayload, err = redash.NewDataSource("My new Redshift data source v2", &redash.RedshiftOptions{
+		Host:     "localhost",
+		Port:     5439,
+		DBName:   "my_database",
+		User:     "user_name",
+		Password: "S3cuR3PaSsW0rD",
+	})
+	if err != nil {
+		log.Fatal(err)
+		return
 	}
 
-	newDataSource, err = c.UpdateDataSource(newDataSource.ID, &postPayload)
+	newDataSource, err = c.UpdateDataSource(newDataSource.ID, postPayload)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -0,0 +1,450 @@
+package redash
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AlertTemplateVariable is a variable Redash fills in custom alert subjects
+// and bodies, written as {{NAME}}
+type AlertTemplateVariable string
+
+// Alert template variables
+const (
+	AlertTemplateAlertName        AlertTemplateVariable = "ALERT_NAME"
+	AlertTemplateAlertURL         AlertTemplateVariable = "ALERT_URL"
+	AlertTemplateAlertStatus      AlertTemplateVariable = "ALERT_STATUS"
+	AlertTemplateAlertCondition   AlertTemplateVariable = "ALERT_CONDITION"
+	AlertTemplateAlertThreshold   AlertTemplateVariable = "ALERT_THRESHOLD"
+	AlertTemplateQueryName        AlertTemplateVariable = "QUERY_NAME"
+	AlertTemplateQueryURL         AlertTemplateVariable = "QUERY_URL"
+	AlertTemplateQueryResultValue AlertTemplateVariable = "QUERY_RESULT_VALUE"
+	AlertTemplateQueryResultRows  AlertTemplateVariable = "QUERY_RESULT_ROWS"
+	AlertTemplateQueryResultCols  AlertTemplateVariable = "QUERY_RESULT_COLS"
+)
+
+var alertTemplateVariables = map[AlertTemplateVariable]bool{
+	AlertTemplateAlertName:        true,
+	AlertTemplateAlertURL:         true,
+	AlertTemplateAlertStatus:      true,
+	AlertTemplateAlertCondition:   true,
+	AlertTemplateAlertThreshold:   true,
+	AlertTemplateQueryName:        true,
+	AlertTemplateQueryURL:         true,
+	AlertTemplateQueryResultValue: true,
+	AlertTemplateQueryResultRows:  true,
+	AlertTemplateQueryResultCols:  true,
+}
+
+// isList reports whether the variable holds a list, whose sections are
+// rendered once per element with the element's fields as extra variables
+func (v AlertTemplateVariable) isList() bool {
+	return v == AlertTemplateQueryResultRows || v == AlertTemplateQueryResultCols
+}
+
+// AlertTemplateError lists the problems found in an alert template
+type AlertTemplateError struct {
+	// Unknown variables used in the template, in order of appearance
+	Unknown []string
+	// Syntax is set when the template cannot be parsed at all
+	Syntax string
+}
+
+func (e *AlertTemplateError) Error() string {
+	if e.Syntax != "" {
+		return "alert template: " + e.Syntax
+	}
+
+	names := make([]string, 0, len(e.Unknown))
+	for _, name := range e.Unknown {
+		names = append(names, "{{"+name+"}}")
+	}
+	return "alert template: unknown variables " + strings.Join(names, ", ")
+}
+
+type alertTemplateNode struct {
+	text     string
+	name     string
+	section  bool
+	inverted bool
+	// raw variables, {{{VAR}}} or {{& VAR}}, are not HTML-escaped
+	raw      bool
+	children []alertTemplateNode
+}
+
+// AlertTemplate is a parsed and validated custom alert subject or body. Redash
+// renders them with Mustache: besides variables, sections such as
+// {{#QUERY_RESULT_ROWS}}{{column}}{{/QUERY_RESULT_ROWS}} repeat their content
+// for every row, and inverted sections {{^VAR}}...{{/VAR}} are only rendered
+// when the variable is empty. Like in Redash, {{VAR}} is HTML-escaped while
+// {{{VAR}}} and {{& VAR}} are output as is.
+type AlertTemplate struct {
+	source string
+	nodes  []alertTemplateNode
+}
+
+// ParseAlertTemplate parses and validates a custom alert subject or body.
+// Outside of row and column sections, only AlertTemplateVariables are allowed.
+func ParseAlertTemplate(source string) (*AlertTemplate, error) {
+	nodes, rest, err := parseAlertTemplateNodes(source, "")
+	if err != nil {
+		return nil, &AlertTemplateError{Syntax: err.Error()}
+	}
+	if rest != "" {
+		return nil, &AlertTemplateError{Syntax: "unexpected content after template"}
+	}
+
+	unknown := []string{}
+	checkAlertTemplateNodes(nodes, &unknown)
+	if len(unknown) > 0 {
+		return nil, &AlertTemplateError{Unknown: unknown}
+	}
+
+	return &AlertTemplate{source: source, nodes: nodes}, nil
+}
+
+// String returns the template source
+func (t *AlertTemplate) String() string {
+	return t.source
+}
+
+// parseAlertTemplateNodes parses until the closing tag of section, or the end
+// of the template at the top level
+func parseAlertTemplateNodes(source, section string) ([]alertTemplateNode, string, error) {
+	nodes := []alertTemplateNode{}
+	for {
+		start := strings.Index(source, "{{")
+		if start < 0 {
+			if section != "" {
+				return nil, "", fmt.Errorf("section {{#%s}} is not closed", section)
+			}
+			if source != "" {
+				nodes = append(nodes, alertTemplateNode{text: source})
+			}
+			return nodes, "", nil
+		}
+		if start > 0 {
+			nodes = append(nodes, alertTemplateNode{text: source[:start]})
+		}
+		source = source[start:]
+
+		closing := "}}"
+		if strings.HasPrefix(source, "{{{") {
+			closing = "}}}"
+		}
+		end := strings.Index(source, closing)
+		if end < 0 {
+			return nil, "", fmt.Errorf("tag %q is not closed", truncate(source, 20))
+		}
+		tag := strings.TrimSpace(source[len(closing):end])
+		source = source[end+len(closing):]
+
+		if closing == "}}}" {
+			nodes = append(nodes, alertTemplateNode{name: tag, raw: true})
+			continue
+		}
+
+		switch {
+		case tag == "":
+			return nil, "", fmt.Errorf("empty tag")
+		case tag[0] == '!':
+			continue
+		case tag[0] == '&':
+			nodes = append(nodes, alertTemplateNode{name: strings.TrimSpace(tag[1:]), raw: true})
+		case tag[0] == '#' || tag[0] == '^':
+			name := strings.TrimSpace(tag[1:])
+			children, rest, err := parseAlertTemplateNodes(source, name)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, alertTemplateNode{name: name, section: true, inverted: tag[0] == '^', children: children})
+			source = rest
+		case tag[0] == '/':
+			name := strings.TrimSpace(tag[1:])
+			if name != section {
+				return nil, "", fmt.Errorf("unexpected {{/%s}}", name)
+			}
+			return nodes, source, nil
+		case tag[0] == '>' || tag[0] == '=':
+			return nil, "", fmt.Errorf("tag {{%s}} is not supported", tag)
+		default:
+			nodes = append(nodes, alertTemplateNode{name: tag})
+		}
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// checkAlertTemplateNodes collects unknown variables. Names within list
+// sections can be row or column fields, so they are not checked.
+func checkAlertTemplateNodes(nodes []alertTemplateNode, unknown *[]string) {
+	for _, node := range nodes {
+		if node.name == "" {
+			continue
+		}
+
+		variable := AlertTemplateVariable(node.name)
+		if !alertTemplateVariables[variable] {
+			if !containsString(*unknown, node.name) {
+				*unknown = append(*unknown, node.name)
+			}
+			continue
+		}
+		if node.section && !(variable.isList() && !node.inverted) {
+			checkAlertTemplateNodes(node.children, unknown)
+		}
+	}
+}
+
+// AlertTemplateBuilder builds alert templates from known variables only:
+//
+//	body, err := redash.NewAlertTemplateBuilder().
+//		Text("Alert ").Var(redash.AlertTemplateAlertName).Text(" is ").Var(redash.AlertTemplateAlertStatus).
+//		EachRow(func(row *redash.AlertTemplateBuilder) { row.Text("\n- ").Column("host") }).
+//		Build()
+type AlertTemplateBuilder struct {
+	builder strings.Builder
+}
+
+// NewAlertTemplateBuilder starts an empty alert template
+func NewAlertTemplateBuilder() *AlertTemplateBuilder {
+	return &AlertTemplateBuilder{}
+}
+
+// Text appends literal text
+func (b *AlertTemplateBuilder) Text(text string) *AlertTemplateBuilder {
+	b.builder.WriteString(text)
+	return b
+}
+
+// Var appends a template variable
+func (b *AlertTemplateBuilder) Var(variable AlertTemplateVariable) *AlertTemplateBuilder {
+	b.builder.WriteString("{{" + string(variable) + "}}")
+	return b
+}
+
+// Column appends the value of a column of the current row, only valid within EachRow
+func (b *AlertTemplateBuilder) Column(name string) *AlertTemplateBuilder {
+	b.builder.WriteString("{{" + name + "}}")
+	return b
+}
+
+// EachRow appends content repeated for every row of the query result
+func (b *AlertTemplateBuilder) EachRow(row func(*AlertTemplateBuilder)) *AlertTemplateBuilder {
+	return b.section(AlertTemplateQueryResultRows, false, row)
+}
+
+// If appends content only rendered when the variable is not empty
+func (b *AlertTemplateBuilder) If(variable AlertTemplateVariable, content func(*AlertTemplateBuilder)) *AlertTemplateBuilder {
+	return b.section(variable, false, content)
+}
+
+// IfEmpty appends content only rendered when the variable is empty
+func (b *AlertTemplateBuilder) IfEmpty(variable AlertTemplateVariable, content func(*AlertTemplateBuilder)) *AlertTemplateBuilder {
+	return b.section(variable, true, content)
+}
+
+func (b *AlertTemplateBuilder) section(variable AlertTemplateVariable, inverted bool, content func(*AlertTemplateBuilder)) *AlertTemplateBuilder {
+	open := "#"
+	if inverted {
+		open = "^"
+	}
+	b.builder.WriteString("{{" + open + string(variable) + "}}")
+	content(b)
+	b.builder.WriteString("{{/" + string(variable) + "}}")
+	return b
+}
+
+// Build validates the template and returns it, ready for
+// AlertOption.CustomSubject or CustomBody
+func (b *AlertTemplateBuilder) Build() (*string, error) {
+	template, err := ParseAlertTemplate(b.builder.String())
+	if err != nil {
+		return nil, err
+	}
+
+	source := template.String()
+	return &source, nil
+}
+
+// validateAlertTemplates checks the custom subject and body of alert options
+func validateAlertTemplates(options *AlertOption) error {
+	for _, template := range []*string{options.CustomSubject, options.CustomBody} {
+		if template == nil {
+			continue
+		}
+		if _, err := ParseAlertTemplate(*template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render previews the template for an alert against a query result, as Redash
+// would render it when the alert changes state. URLs are built from
+// redashURI. Rows and columns are rendered as JSON, which differs slightly
+// from the Python formatting Redash uses.
+func (t *AlertTemplate) Render(redashURI string, alert *Alert, result *QueryResult) (string, error) {
+	state, err := EvaluateAlert(alert, result)
+	if err != nil {
+		return "", err
+	}
+
+	redashURI = strings.TrimSuffix(redashURI, "/")
+	values := map[string]interface{}{
+		string(AlertTemplateAlertName):      alert.Name,
+		string(AlertTemplateAlertURL):       redashURI + "/alerts/" + strconv.Itoa(alert.ID),
+		string(AlertTemplateAlertStatus):    strings.ToUpper(string(state)),
+		string(AlertTemplateAlertCondition): string(alert.Options.Op),
+		string(AlertTemplateAlertThreshold): alert.Options.Value,
+		string(AlertTemplateQueryName):      alert.Query.Name,
+		string(AlertTemplateQueryURL):       redashURI + "/queries/" + strconv.Itoa(alert.Query.ID),
+	}
+
+	var rows []interface{}
+	var cols []interface{}
+	if result != nil {
+		for _, row := range result.Data.Rows {
+			rows = append(rows, row)
+		}
+		for _, column := range result.Data.Columns {
+			cols = append(cols, map[string]interface{}{
+				"name":          column.Name,
+				"friendly_name": column.FriendlyName,
+				"type":          column.Type,
+			})
+		}
+		if len(result.Data.Rows) > 0 {
+			values[string(AlertTemplateQueryResultValue)] = result.Data.Rows[0][alert.Options.Column]
+		}
+	}
+	values[string(AlertTemplateQueryResultRows)] = rows
+	values[string(AlertTemplateQueryResultCols)] = cols
+
+	var output strings.Builder
+	renderAlertTemplateNodes(&output, t.nodes, []map[string]interface{}{values})
+	return output.String(), nil
+}
+
+// RenderAlertTemplate parses a custom alert subject or body and previews it
+// for an alert against a query result, see AlertTemplate.Render
+func (c *Client) RenderAlertTemplate(template string, alert *Alert, result *QueryResult) (string, error) {
+	parsed, err := ParseAlertTemplate(template)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Render(c.Config.RedashURI, alert, result)
+}
+
+// renderAlertTemplateNodes renders nodes, looking names up from the innermost
+// context outwards
+func renderAlertTemplateNodes(output *strings.Builder, nodes []alertTemplateNode, contexts []map[string]interface{}) {
+	for _, node := range nodes {
+		if node.name == "" {
+			output.WriteString(node.text)
+			continue
+		}
+
+		var value interface{}
+		for i := len(contexts) - 1; i >= 0; i-- {
+			if v, exists := contexts[i][node.name]; exists {
+				value = v
+				break
+			}
+		}
+
+		if !node.section {
+			if node.raw {
+				output.WriteString(formatAlertTemplateValue(value))
+			} else {
+				output.WriteString(alertTemplateEscaper.Replace(formatAlertTemplateValue(value)))
+			}
+			continue
+		}
+
+		if node.inverted {
+			if !truthyAlertTemplateValue(value) {
+				renderAlertTemplateNodes(output, node.children, contexts)
+			}
+			continue
+		}
+		if !truthyAlertTemplateValue(value) {
+			continue
+		}
+
+		items, isList := value.([]interface{})
+		if !isList {
+			items = []interface{}{value}
+		}
+		for _, item := range items {
+			scope := contexts
+			if fields, ok := item.(map[string]interface{}); ok {
+				scope = append(contexts[:len(contexts):len(contexts)], fields)
+			}
+			renderAlertTemplateNodes(output, node.children, scope)
+		}
+	}
+}
+
+// alertTemplateEscaper escapes like Python's html.escape, which pystache
+// applies to {{VAR}} when Redash renders the template
+var alertTemplateEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&#x27;",
+)
+
+func truthyAlertTemplateValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	if number, ok := toFloat(value); ok {
+		return number != 0
+	}
+	return true
+}
+
+func formatAlertTemplateValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}, map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+	return fmt.Sprint(value)
+}
+
+// AlertTemplateVariables returns the names of all known template variables, sorted
+func AlertTemplateVariables() []AlertTemplateVariable {
+	variables := make([]AlertTemplateVariable, 0, len(alertTemplateVariables))
+	for variable := range alertTemplateVariables {
+		variables = append(variables, variable)
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i] < variables[j] })
+	return variables
+}
@@ -0,0 +1,118 @@
+package redash
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAlertTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	template, err := ParseAlertTemplate("{{ ALERT_NAME }} is {{{ALERT_STATUS}}}{{! comment }}{{#QUERY_RESULT_ROWS}}{{host}}{{/QUERY_RESULT_ROWS}}")
+	assert.Nil(err)
+	assert.Equal("{{ ALERT_NAME }} is {{{ALERT_STATUS}}}{{! comment }}{{#QUERY_RESULT_ROWS}}{{host}}{{/QUERY_RESULT_ROWS}}", template.String())
+
+	_, err = ParseAlertTemplate("{{ALERT_NAM}} {{QUERY_RESULT}} {{ALERT_NAM}} {{^QUERY_RESULT_ROWS}}{{ROWS}}{{/QUERY_RESULT_ROWS}}")
+	var templateErr *AlertTemplateError
+	assert.True(errors.As(err, &templateErr))
+	assert.Equal([]string{"ALERT_NAM", "QUERY_RESULT", "ROWS"}, templateErr.Unknown)
+	assert.EqualError(err, "alert template: unknown variables {{ALERT_NAM}}, {{QUERY_RESULT}}, {{ROWS}}")
+
+	_, err = ParseAlertTemplate("{{#QUERY_RESULT_ROWS}}{{host}}")
+	assert.EqualError(err, "alert template: section {{#QUERY_RESULT_ROWS}} is not closed")
+
+	_, err = ParseAlertTemplate("{{#QUERY_RESULT_ROWS}}{{/QUERY_RESULT_COLS}}")
+	assert.EqualError(err, "alert template: unexpected {{/QUERY_RESULT_COLS}}")
+
+	_, err = ParseAlertTemplate("Value: {{QUERY_RESULT_VALUE")
+	assert.EqualError(err, `alert template: tag "{{QUERY_RESULT_VALUE" is not closed`)
+
+	_, err = ParseAlertTemplate("{{> partial}}")
+	assert.EqualError(err, "alert template: tag {{> partial}} is not supported")
+}
+
+func TestAlertTemplateBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	body, err := NewAlertTemplateBuilder().
+		Text("Alert ").Var(AlertTemplateAlertName).Text(" is ").Var(AlertTemplateAlertStatus).
+		EachRow(func(row *AlertTemplateBuilder) { row.Text("\n- ").Column("host") }).
+		IfEmpty(AlertTemplateQueryResultRows, func(b *AlertTemplateBuilder) { b.Text("no rows") }).
+		Build()
+	assert.Nil(err)
+	assert.Equal("Alert {{ALERT_NAME}} is {{ALERT_STATUS}}{{#QUERY_RESULT_ROWS}}\n- {{host}}{{/QUERY_RESULT_ROWS}}{{^QUERY_RESULT_ROWS}}no rows{{/QUERY_RESULT_ROWS}}", *body)
+
+	_, err = NewAlertTemplateBuilder().Var("ALERT_NAMES").Build()
+	assert.EqualError(err, "alert template: unknown variables {{ALERT_NAMES}}")
+}
+
+func TestRenderAlertTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	alert := &Alert{
+		ID:      3,
+		Name:    "Errors",
+		Options: AlertOption{Op: AlertOperatorGreaterThan, Column: "errors", Value: 10},
+		Query:   Query{ID: 5, Name: "Error count"},
+	}
+	result := &QueryResult{}
+	result.Data.Columns = []QueryResultColumn{{Name: "host", Type: ColumnTypeString}, {Name: "errors", Type: ColumnTypeInteger}}
+	result.Data.Rows = []map[string]interface{}{
+		{"host": "web-1", "errors": 42.0},
+		{"host": "web-2", "errors": 3.0},
+	}
+
+	subject, err := c.RenderAlertTemplate("[{{ALERT_STATUS}}] {{ALERT_NAME}}: {{QUERY_RESULT_VALUE}} {{ALERT_CONDITION}} {{ALERT_THRESHOLD}}", alert, result)
+	assert.Nil(err)
+	assert.Equal("[TRIGGERED] Errors: 42 &gt; 10", subject)
+
+	body, err := c.RenderAlertTemplate("{{QUERY_NAME}} <{{QUERY_URL}}> <{{ALERT_URL}}>\n{{#QUERY_RESULT_ROWS}}{{host}}={{errors}} ({{ALERT_NAME}})\n{{/QUERY_RESULT_ROWS}}{{#QUERY_RESULT_COLS}}{{name}}:{{type}} {{/QUERY_RESULT_COLS}}", alert, result)
+	assert.Nil(err)
+	assert.Equal("Error count <https://com.acme/queries/5> <https://com.acme/alerts/3>\nweb-1=42 (Errors)\nweb-2=3 (Errors)\nhost:string errors:integer ", body)
+
+	rows, err := c.RenderAlertTemplate("{{{QUERY_RESULT_ROWS}}}", alert, result)
+	assert.Nil(err)
+	assert.Equal(`[{"errors":42,"host":"web-1"},{"errors":3,"host":"web-2"}]`, rows)
+
+	// {{VAR}} is HTML-escaped like pystache does, {{{VAR}}} and {{& VAR}} are not
+	alert.Name = `<b>"Errors" & 'warnings'</b>`
+	escaped, err := c.RenderAlertTemplate("{{ALERT_NAME}}|{{{ALERT_NAME}}}|{{& ALERT_NAME}}|{{QUERY_RESULT_ROWS}}", alert, result)
+	assert.Nil(err)
+	assert.Equal(`&lt;b&gt;&quot;Errors&quot; &amp; &#x27;warnings&#x27;&lt;/b&gt;|<b>"Errors" & 'warnings'</b>|<b>"Errors" & 'warnings'</b>|`+
+		`[{&quot;errors&quot;:42,&quot;host&quot;:&quot;web-1&quot;},{&quot;errors&quot;:3,&quot;host&quot;:&quot;web-2&quot;}]`, escaped)
+
+	empty, err := c.RenderAlertTemplate("{{ALERT_STATUS}}{{^QUERY_RESULT_ROWS}}: no data{{/QUERY_RESULT_ROWS}}", alert, &QueryResult{})
+	assert.Nil(err)
+	assert.Equal("UNKNOWN: no data", empty)
+}
+
+func TestCreateAlertInvalidTemplate(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	body := "{{QUERY_RESULT_VALUES}}"
+	_, err := c.CreateAlert(CreateAlertPayload{
+		Name:    "Errors",
+		QueryId: 5,
+		Options: AlertOption{Op: AlertOperatorGreaterThan, Column: "errors", Value: 10, CustomBody: &body},
+	})
+	assert.EqualError(err, "alert template: unknown variables {{QUERY_RESULT_VALUES}}")
+
+	_, err = c.UpdateAlert(3, &UpdateAlertPayload{Options: AlertOption{CustomSubject: &body}})
+	assert.EqualError(err, "alert template: unknown variables {{QUERY_RESULT_VALUES}}")
+	assert.Equal(0, httpmock.GetTotalCallCount())
+	// a nil payload has no templates to check and is sent as is
+	httpmock.RegisterResponder("POST", "https://com.acme/api/alerts/3",
+		httpmock.NewStringResponder(200, `{"id": 3, "name": "Errors"}`))
+	alert, err := c.UpdateAlert(3, nil)
+	assert.Nil(err)
+	assert.Equal(3, alert.ID)
+}
@@ -1,10 +1,14 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,7 +16,7 @@ type Alert struct {
 	ID              int         `json:"id,omitempty"`
 	Name            string      `json:"name,omitempty"`
 	Options         AlertOption `json:"options,omitempty"`
-	State           string      `json:"state,omitempty"`
+	State           AlertState  `json:"state,omitempty"`
 	LastTriggeredAt *time.Time  `json:"last_triggered_at,omitempty"`
 	UpdatedAt       time.Time   `json:"updated_at,omitempty"`
 	CreatedAt       time.Time   `json:"created_at,omitempty"`
@@ -21,13 +25,57 @@ type Alert struct {
 	User            User        `json:"user,omitempty"`
 }
 
+// AlertOption configures when an alert triggers. CustomSubject and CustomBody
+// are Mustache templates, see AlertTemplate.
 type AlertOption struct {
-	Op            string      `json:"op,omitempty"`
-	Value         interface{} `json:"value,omitempty"`
-	Muted         bool        `json:"muted,omitempty"`
-	Column        string      `json:"column,omitempty"`
-	CustomBody    *string     `json:"custom_body,omitempty"`
-	CustomSubject *string     `json:"custom_subject,omitempty"`
+	Op            AlertOperator `json:"op,omitempty"`
+	Value         interface{}   `json:"value,omitempty"`
+	Muted         bool          `json:"muted,omitempty"`
+	Column        string        `json:"column,omitempty"`
+	CustomBody    *string       `json:"custom_body,omitempty"`
+	CustomSubject *string       `json:"custom_subject,omitempty"`
+}
+
+// AlertState is the outcome of the last evaluation of an alert
+type AlertState string
+
+// Alert states
+const (
+	AlertStateOK        AlertState = "ok"
+	AlertStateTriggered AlertState = "triggered"
+	AlertStateUnknown   AlertState = "unknown"
+)
+
+// AlertOperator compares the alert column of a query result with the alert threshold
+type AlertOperator string
+
+// Alert operators
+const (
+	AlertOperatorGreaterThan        AlertOperator = ">"
+	AlertOperatorLessThan           AlertOperator = "<"
+	AlertOperatorEqual              AlertOperator = "=="
+	AlertOperatorNotEqual           AlertOperator = "!="
+	AlertOperatorGreaterThanOrEqual AlertOperator = ">="
+	AlertOperatorLessThanOrEqual    AlertOperator = "<="
+)
+
+// legacyAlertOperators are the operator names used by alerts created before Redash 8
+var legacyAlertOperators = map[AlertOperator]AlertOperator{
+	"greater than": AlertOperatorGreaterThan,
+	"less than":    AlertOperatorLessThan,
+	"equals":       AlertOperatorEqual,
+}
+
+// Valid reports whether Redash knows the operator, including the legacy
+// "greater than", "less than" and "equals" names
+func (o AlertOperator) Valid() bool {
+	switch o {
+	case AlertOperatorGreaterThan, AlertOperatorLessThan, AlertOperatorEqual,
+		AlertOperatorNotEqual, AlertOperatorGreaterThanOrEqual, AlertOperatorLessThanOrEqual:
+		return true
+	}
+	_, legacy := legacyAlertOperators[o]
+	return legacy
 }
 
 type CreateAlertPayload struct {
@@ -44,22 +92,63 @@ type UpdateAlertPayload struct {
 	Rearm   *int        `json:"rearm,omitempty"`
 }
 
+// CreateAlertSubscriptionPayload subscribes to an alert. Without a
+// DestinationId the user owning the API key is notified by email.
 type CreateAlertSubscriptionPayload struct {
 	AlertId       int `json:"alert_id,omitempty"`
 	DestinationId int `json:"destination_id,omitempty"`
 }
 
+// AlertSubscription notifies a destination, or when Destination is nil the
+// subscribing User by email, when an alert changes state
 type AlertSubscription struct {
-	Id          int         `json:"id,omitempty"`
-	AlertId     int         `json:"alert_id,omitempty"`
-	User        User        `json:"user,omitempty"`
-	Destination Destination `json:"destination,omitempty"`
+	Id          int          `json:"id,omitempty"`
+	AlertId     int          `json:"alert_id,omitempty"`
+	User        User         `json:"user,omitempty"`
+	Destination *Destination `json:"destination,omitempty"`
+}
+
+// Subscriber returns who the subscription notifies
+func (s *AlertSubscription) Subscriber() AlertSubscriber {
+	if s.Destination != nil {
+		return AlertSubscriber{DestinationID: s.Destination.Id}
+	}
+	return AlertSubscriber{UserID: s.User.ID}
+}
+
+// AlertSubscriber is who an alert notifies: a destination, or a user by
+// email. Redash only lets a client subscribe the user owning its API key,
+// which a zero UserID stands for.
+type AlertSubscriber struct {
+	DestinationID int
+	UserID        int
+}
+
+// DestinationSubscriber notifies the destination with the given ID
+func DestinationSubscriber(id int) AlertSubscriber {
+	return AlertSubscriber{DestinationID: id}
+}
+
+// EmailSubscriber notifies the user owning the API key by email
+func EmailSubscriber() AlertSubscriber {
+	return AlertSubscriber{}
+}
+
+// AlertSubscriptionChanges lists what SyncAlertSubscriptions changed
+type AlertSubscriptionChanges struct {
+	Added   []AlertSubscription
+	Removed []AlertSubscription
 }
 
 func (c *Client) GetAlerts() (*[]Alert, error) {
+	return c.GetAlertsContext(context.Background())
+}
+
+// GetAlertsContext is like GetAlerts but uses ctx for the underlying requests.
+func (c *Client) GetAlertsContext(ctx context.Context) (*[]Alert, error) {
 	path := "/api/alerts"
 	query := url.Values{}
-	response, err := c.get(path, query)
+	response, err := c.get(ctx, path, query)
 
 	if err != nil {
 		return nil, err
@@ -76,10 +165,42 @@ func (c *Client) GetAlerts() (*[]Alert, error) {
 	return &alerts, nil
 }
 
+// AlertsPager iterates over every alert. Redash does not paginate alerts, so
+// they are all fetched on the first call to Next.
+func (c *Client) AlertsPager() *Pager[Alert] {
+	return c.AlertsPagerContext(context.Background())
+}
+
+// AlertsPagerContext is like AlertsPager but uses ctx for the underlying requests.
+func (c *Client) AlertsPagerContext(ctx context.Context) *Pager[Alert] {
+	return NewPager(ctx, 1, 0, func(ctx context.Context, page int) ([]Alert, int, error) {
+		alerts, err := c.GetAlertsContext(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		return *alerts, len(*alerts), nil
+	})
+}
+
+// ListAllAlerts returns every alert, up to limit alerts when limit is positive
+func (c *Client) ListAllAlerts(limit int) ([]Alert, error) {
+	return c.ListAllAlertsContext(context.Background(), limit)
+}
+
+// ListAllAlertsContext is like ListAllAlerts but uses ctx for the underlying requests.
+func (c *Client) ListAllAlertsContext(ctx context.Context, limit int) ([]Alert, error) {
+	return ListAll(c.AlertsPagerContext(ctx), limit)
+}
+
 func (c *Client) GetAlert(id int) (*Alert, error) {
+	return c.GetAlertContext(context.Background(), id)
+}
+
+// GetAlertContext is like GetAlert but uses ctx for the underlying requests.
+func (c *Client) GetAlertContext(ctx context.Context, id int) (*Alert, error) {
 	path := "/api/alerts/" + strconv.Itoa(id)
 	query := url.Values{}
-	response, err := c.get(path, query)
+	response, err := c.get(ctx, path, query)
 
 	if err != nil {
 		return nil, err
@@ -97,15 +218,24 @@ func (c *Client) GetAlert(id int) (*Alert, error) {
 }
 
 func (c *Client) CreateAlert(createAlert CreateAlertPayload) (*Alert, error) {
+	return c.CreateAlertContext(context.Background(), createAlert)
+}
+
+// CreateAlertContext is like CreateAlert but uses ctx for the underlying requests.
+func (c *Client) CreateAlertContext(ctx context.Context, createAlert CreateAlertPayload) (*Alert, error) {
 	path := "/api/alerts"
 
+	if err := validateAlertTemplates(&createAlert.Options); err != nil {
+		return nil, err
+	}
+
 	payload, err := json.Marshal(createAlert)
 	if err != nil {
 		return nil, err
 	}
 
 	query := url.Values{}
-	res, err := c.post(path, string(payload), query)
+	res, err := c.post(ctx, path, string(payload), query)
 
 	if err != nil {
 		return nil, err
@@ -126,15 +256,26 @@ func (c *Client) CreateAlert(createAlert CreateAlertPayload) (*Alert, error) {
 }
 
 func (c *Client) UpdateAlert(id int, updateAlertPayload *UpdateAlertPayload) (*Alert, error) {
+	return c.UpdateAlertContext(context.Background(), id, updateAlertPayload)
+}
+
+// UpdateAlertContext is like UpdateAlert but uses ctx for the underlying requests.
+func (c *Client) UpdateAlertContext(ctx context.Context, id int, updateAlertPayload *UpdateAlertPayload) (*Alert, error) {
 	path := "/api/alerts/" + strconv.Itoa(id)
 
+	if updateAlertPayload != nil {
+		if err := validateAlertTemplates(&updateAlertPayload.Options); err != nil {
+			return nil, err
+		}
+	}
+
 	payload, err := json.Marshal(updateAlertPayload)
 	if err != nil {
 		return nil, err
 	}
 
 	query := url.Values{}
-	res, err := c.post(path, string(payload), query)
+	res, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return nil, err
 	}
@@ -155,20 +296,31 @@ func (c *Client) UpdateAlert(id int, updateAlertPayload *UpdateAlertPayload) (*A
 }
 
 func (c *Client) DeleteAlert(id int) error {
+	return c.DeleteAlertContext(context.Background(), id)
+}
+
+// DeleteAlertContext is like DeleteAlert but uses ctx for the underlying requests.
+func (c *Client) DeleteAlertContext(ctx context.Context, id int) error {
 	path := "/api/alerts/" + strconv.Itoa(id)
 
-	_, err := c.delete(path, url.Values{})
+	response, err := c.delete(ctx, path, url.Values{})
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 
 	return nil
 }
 
 func (c *Client) GetAlertSubscriptions(id int) (*[]AlertSubscription, error) {
+	return c.GetAlertSubscriptionsContext(context.Background(), id)
+}
+
+// GetAlertSubscriptionsContext is like GetAlertSubscriptions but uses ctx for the underlying requests.
+func (c *Client) GetAlertSubscriptionsContext(ctx context.Context, id int) (*[]AlertSubscription, error) {
 	path := "/api/alerts/" + strconv.Itoa(id) + "/subscriptions"
 
-	res, err := c.get(path, url.Values{})
+	res, err := c.get(ctx, path, url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -188,6 +340,11 @@ func (c *Client) GetAlertSubscriptions(id int) (*[]AlertSubscription, error) {
 }
 
 func (c *Client) CreateAlertSubscription(createAlertSubsciptionPayload CreateAlertSubscriptionPayload) (*AlertSubscription, error) {
+	return c.CreateAlertSubscriptionContext(context.Background(), createAlertSubsciptionPayload)
+}
+
+// CreateAlertSubscriptionContext is like CreateAlertSubscription but uses ctx for the underlying requests.
+func (c *Client) CreateAlertSubscriptionContext(ctx context.Context, createAlertSubsciptionPayload CreateAlertSubscriptionPayload) (*AlertSubscription, error) {
 	path := "/api/alerts/" + strconv.Itoa(createAlertSubsciptionPayload.AlertId) + "/subscriptions"
 
 	payload, err := json.Marshal(createAlertSubsciptionPayload)
@@ -195,7 +352,7 @@ func (c *Client) CreateAlertSubscription(createAlertSubsciptionPayload CreateAle
 		return nil, err
 	}
 
-	res, err := c.post(path, string(payload), url.Values{})
+	res, err := c.post(ctx, path, string(payload), url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -215,11 +372,239 @@ func (c *Client) CreateAlertSubscription(createAlertSubsciptionPayload CreateAle
 }
 
 func (c *Client) DeleteAlertSubscription(alertId int, subscriptionId int) error {
-	path := "/api/alerts/" + strconv.Itoa(alertId) + "/subscriptions" + strconv.Itoa(subscriptionId)
+	return c.DeleteAlertSubscriptionContext(context.Background(), alertId, subscriptionId)
+}
+
+// DeleteAlertSubscriptionContext is like DeleteAlertSubscription but uses ctx for the underlying requests.
+func (c *Client) DeleteAlertSubscriptionContext(ctx context.Context, alertId int, subscriptionId int) error {
+	path := "/api/alerts/" + strconv.Itoa(alertId) + "/subscriptions/" + strconv.Itoa(subscriptionId)
 
-	_, err := c.delete(path, url.Values{})
+	response, err := c.delete(ctx, path, url.Values{})
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 	return nil
 }
+
+// SyncAlertSubscriptions adds and removes subscriptions of an alert until its
+// subscribers are exactly the desired ones. Running it again with the same
+// subscribers changes nothing. Email subscriptions of users other than the
+// API key's owner can be kept or removed, but not added.
+func (c *Client) SyncAlertSubscriptions(alertID int, desired []AlertSubscriber) (*AlertSubscriptionChanges, error) {
+	return c.SyncAlertSubscriptionsContext(context.Background(), alertID, desired)
+}
+
+// SyncAlertSubscriptionsContext is like SyncAlertSubscriptions but uses ctx for the underlying requests.
+func (c *Client) SyncAlertSubscriptionsContext(ctx context.Context, alertID int, desired []AlertSubscriber) (*AlertSubscriptionChanges, error) {
+	current, err := c.GetAlertSubscriptionsContext(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentUser *User
+	currentUserID := func() (int, error) {
+		if currentUser == nil {
+			user, err := c.GetCurrentUserContext(ctx)
+			if err != nil {
+				return 0, err
+			}
+			currentUser = user
+		}
+		return currentUser.ID, nil
+	}
+
+	wanted := map[AlertSubscriber]bool{}
+	for _, subscriber := range desired {
+		if subscriber.DestinationID != 0 {
+			subscriber.UserID = 0
+		} else if subscriber.UserID == 0 {
+			subscriber.UserID, err = currentUserID()
+			if err != nil {
+				return nil, err
+			}
+		}
+		wanted[subscriber] = true
+	}
+
+	changes := &AlertSubscriptionChanges{}
+	existing := map[AlertSubscriber]bool{}
+	var stale []AlertSubscription
+	for _, subscription := range *current {
+		subscriber := subscription.Subscriber()
+		if !wanted[subscriber] || existing[subscriber] {
+			stale = append(stale, subscription)
+			continue
+		}
+		existing[subscriber] = true
+	}
+
+	missing := []AlertSubscriber{}
+	for subscriber := range wanted {
+		if existing[subscriber] {
+			continue
+		}
+		if subscriber.DestinationID == 0 {
+			userID, err := currentUserID()
+			if err != nil {
+				return nil, err
+			}
+			if subscriber.UserID != userID {
+				return nil, fmt.Errorf("alert %d: cannot subscribe user %d, only the API key's owner can be subscribed by email", alertID, subscriber.UserID)
+			}
+		}
+		missing = append(missing, subscriber)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].DestinationID != missing[j].DestinationID {
+			return missing[i].DestinationID < missing[j].DestinationID
+		}
+		return missing[i].UserID < missing[j].UserID
+	})
+
+	// subscribe first, so nobody misses a notification while the alert is being synced
+	for _, subscriber := range missing {
+		subscription, err := c.CreateAlertSubscriptionContext(ctx, CreateAlertSubscriptionPayload{
+			AlertId:       alertID,
+			DestinationId: subscriber.DestinationID,
+		})
+		if err != nil {
+			return changes, err
+		}
+		changes.Added = append(changes.Added, *subscription)
+	}
+
+	for _, subscription := range stale {
+		err := c.DeleteAlertSubscriptionContext(ctx, alertID, subscription.Id)
+		if err != nil {
+			return changes, err
+		}
+		changes.Removed = append(changes.Removed, subscription)
+	}
+
+	return changes, nil
+}
+
+// MuteAlert stops an alert from sending notifications. It keeps being
+// evaluated, so its state stays current.
+func (c *Client) MuteAlert(id int) error {
+	return c.MuteAlertContext(context.Background(), id)
+}
+
+// MuteAlertContext is like MuteAlert but uses ctx for the underlying requests.
+func (c *Client) MuteAlertContext(ctx context.Context, id int) error {
+	path := "/api/alerts/" + strconv.Itoa(id) + "/mute"
+
+	response, err := c.post(ctx, path, "", url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// UnmuteAlert lets a muted alert send notifications again
+func (c *Client) UnmuteAlert(id int) error {
+	return c.UnmuteAlertContext(context.Background(), id)
+}
+
+// UnmuteAlertContext is like UnmuteAlert but uses ctx for the underlying requests.
+func (c *Client) UnmuteAlertContext(ctx context.Context, id int) error {
+	path := "/api/alerts/" + strconv.Itoa(id) + "/mute"
+
+	response, err := c.delete(ctx, path, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// EvaluateAlert returns the state the alert would be in against a query
+// result, following the rules Redash applies on the server: the alert column
+// of the first row is compared with the threshold, as numbers when both are
+// numeric and as strings otherwise. A result without rows or without the
+// column is unknown. Muting an alert doesn't change its state.
+func EvaluateAlert(alert *Alert, result *QueryResult) (AlertState, error) {
+	op := alert.Options.Op
+	if legacy, ok := legacyAlertOperators[op]; ok {
+		op = legacy
+	}
+	if !op.Valid() {
+		return AlertStateUnknown, fmt.Errorf("alert %d: unknown operator %q", alert.ID, string(alert.Options.Op))
+	}
+
+	if result == nil || len(result.Data.Rows) == 0 {
+		return AlertStateUnknown, nil
+	}
+	value, exists := result.Data.Rows[0][alert.Options.Column]
+	if !exists {
+		return AlertStateUnknown, nil
+	}
+
+	threshold := alert.Options.Value
+	var comparison int
+	if number, ok := alertNumber(value); ok {
+		limit, ok := alertNumber(threshold)
+		if !ok {
+			return AlertStateUnknown, nil
+		}
+		comparison = compareFloats(number, limit)
+	} else {
+		comparison = strings.Compare(alertString(value), alertString(threshold))
+	}
+
+	var triggered bool
+	switch op {
+	case AlertOperatorGreaterThan:
+		triggered = comparison > 0
+	case AlertOperatorLessThan:
+		triggered = comparison < 0
+	case AlertOperatorEqual:
+		triggered = comparison == 0
+	case AlertOperatorNotEqual:
+		triggered = comparison != 0
+	case AlertOperatorGreaterThanOrEqual:
+		triggered = comparison >= 0
+	case AlertOperatorLessThanOrEqual:
+		triggered = comparison <= 0
+	}
+
+	if triggered {
+		return AlertStateTriggered, nil
+	}
+	return AlertStateOK, nil
+}
+
+// alertNumber converts numbers and numeric strings, but not booleans, to a float64
+func alertNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case bool, nil:
+		return 0, false
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return toFloat(value)
+}
+
+// alertString formats a value the way Redash does before a string comparison,
+// with booleans in lower case
+func alertString(value interface{}) string {
+	if value == nil {
+		return "None"
+	}
+	return fmt.Sprint(value)
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -1,6 +1,8 @@
 package redash
 
 import (
+	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
@@ -289,3 +291,134 @@ func TestCreateAlert(t *testing.T) {
 	assert.Equal(AlertOption{Op: ">", Value: 1.0, Muted: false, Column: "col1"}, alert.Options)
 
 }
+
+func TestMuteAlert(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/alerts/1/mute",
+		httpmock.NewStringResponder(200, "null"))
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/alerts/1/mute",
+		httpmock.NewStringResponder(200, "null"))
+
+	assert.Nil(c.MuteAlert(1))
+	assert.Nil(c.UnmuteAlert(1))
+
+	info := httpmock.GetCallCountInfo()
+	assert.Equal(1, info["POST https://com.acme/api/alerts/1/mute"])
+	assert.Equal(1, info["DELETE https://com.acme/api/alerts/1/mute"])
+}
+
+func TestEvaluateAlert(t *testing.T) {
+	assert := assert.New(t)
+
+	result := &QueryResult{}
+	result.Data.Rows = []map[string]interface{}{
+		{"count": 42.0, "status": "degraded", "healthy": false, "ratio": "0.25"},
+		{"count": 1.0},
+	}
+
+	tests := []struct {
+		op     AlertOperator
+		column string
+		value  interface{}
+		state  AlertState
+	}{
+		{AlertOperatorGreaterThan, "count", 40, AlertStateTriggered},
+		{AlertOperatorGreaterThan, "count", "50", AlertStateOK},
+		{AlertOperatorLessThanOrEqual, "count", 42.0, AlertStateTriggered},
+		{AlertOperatorNotEqual, "count", "42", AlertStateOK},
+		{AlertOperatorGreaterThanOrEqual, "ratio", 0.2, AlertStateTriggered},
+		{AlertOperatorEqual, "status", "degraded", AlertStateTriggered},
+		{AlertOperatorLessThan, "status", "critical", AlertStateOK},
+		{AlertOperatorEqual, "healthy", "false", AlertStateTriggered},
+		{AlertOperator("greater than"), "count", 10, AlertStateTriggered},
+		{AlertOperatorGreaterThan, "count", "many", AlertStateUnknown},
+		{AlertOperatorGreaterThan, "missing", 1, AlertStateUnknown},
+	}
+	for _, test := range tests {
+		alert := &Alert{Options: AlertOption{Op: test.op, Column: test.column, Value: test.value}}
+		state, err := EvaluateAlert(alert, result)
+		assert.Nil(err)
+		assert.Equal(test.state, state, "%s %s %v", test.column, test.op, test.value)
+	}
+
+	state, err := EvaluateAlert(&Alert{Options: AlertOption{Op: ">", Column: "count", Value: 1}}, &QueryResult{})
+	assert.Nil(err)
+	assert.Equal(AlertStateUnknown, state)
+
+	_, err = EvaluateAlert(&Alert{ID: 7, Options: AlertOption{Op: "~", Column: "count"}}, result)
+	assert.EqualError(err, `alert 7: unknown operator "~"`)
+	assert.False(AlertOperator("~").Valid())
+	assert.True(AlertOperator("equals").Valid())
+}
+
+func TestDeleteAlertSubscription(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/alerts/1/subscriptions/3",
+		httpmock.NewStringResponder(200, "null"))
+
+	assert.Nil(c.DeleteAlertSubscription(1, 3))
+	assert.Equal(1, httpmock.GetCallCountInfo()["DELETE https://com.acme/api/alerts/1/subscriptions/3"])
+}
+
+const alertSubscriptionsResponse = `[
+	{"id": 1, "alert_id": 1, "user": {"id": 7, "email": "admin@acme.com"}, "destination": {"id": 5, "name": "Slack", "type": "slack"}},
+	{"id": 2, "alert_id": 1, "user": {"id": 7, "email": "admin@acme.com"}},
+	{"id": 3, "alert_id": 1, "user": {"id": 7, "email": "admin@acme.com"}, "destination": {"id": 6, "name": "PagerDuty", "type": "pagerduty"}},
+	{"id": 4, "alert_id": 1, "user": {"id": 8, "email": "dev@acme.com"}, "destination": {"id": 5, "name": "Slack", "type": "slack"}}
+]`
+
+func TestSyncAlertSubscriptions(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/alerts/1/subscriptions",
+		httpmock.NewStringResponder(200, alertSubscriptionsResponse))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/session",
+		httpmock.NewStringResponder(200, `{"user": {"id": 7, "name": "Admin", "email": "admin@acme.com", "groups": [1, 2]}, "org_slug": "default"}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/alerts/1/subscriptions",
+		func(request *http.Request) (*http.Response, error) {
+			payload := CreateAlertSubscriptionPayload{}
+			assert.Nil(json.NewDecoder(request.Body).Decode(&payload))
+			assert.Equal(9, payload.DestinationId)
+			return httpmock.NewStringResponse(200, `{"id": 5, "alert_id": 1, "user": {"id": 7}, "destination": {"id": 9, "name": "Email", "type": "email"}}`), nil
+		})
+	httpmock.RegisterResponder("DELETE", `=~^https://com.acme/api/alerts/1/subscriptions/\d+\z`,
+		httpmock.NewStringResponder(200, "null"))
+
+	changes, err := c.SyncAlertSubscriptions(1, []AlertSubscriber{
+		DestinationSubscriber(5),
+		DestinationSubscriber(9),
+		EmailSubscriber(),
+	})
+	assert.Nil(err)
+	assert.Equal(1, len(changes.Added))
+	assert.Equal(&Destination{Id: 9, Name: "Email", Type: "email"}, changes.Added[0].Destination)
+	assert.Equal([]int{3, 4}, []int{changes.Removed[0].Id, changes.Removed[1].Id})
+
+	info := httpmock.GetCallCountInfo()
+	assert.Equal(1, info["GET https://com.acme/api/session"])
+	assert.Equal(1, info["DELETE https://com.acme/api/alerts/1/subscriptions/3"])
+	assert.Equal(1, info["DELETE https://com.acme/api/alerts/1/subscriptions/4"])
+	assert.Equal(0, info["DELETE https://com.acme/api/alerts/1/subscriptions/1"])
+	assert.Equal(0, info["DELETE https://com.acme/api/alerts/1/subscriptions/2"])
+
+	httpmock.ZeroCallCounters()
+	_, err = c.SyncAlertSubscriptions(1, []AlertSubscriber{{UserID: 8}})
+	assert.EqualError(err, "alert 1: cannot subscribe user 8, only the API key's owner can be subscribed by email")
+	info = httpmock.GetCallCountInfo()
+	assert.Equal(0, info["POST https://com.acme/api/alerts/1/subscriptions"])
+	assert.Equal(0, info[`DELETE =~^https://com.acme/api/alerts/1/subscriptions/\d+\z`])
+}
@@ -15,6 +15,7 @@
 package redash
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -27,6 +28,9 @@ import (
 // Client contains an active Redash API client
 type Client struct {
 	Config *Config
+
+	httpClient *http.Client
+	limiter    *limiter
 }
 
 // Config holds the necessary setup vars
@@ -34,6 +38,30 @@ type Config struct {
 	RedashURI  string
 	APIKey     string
 	StrictMode bool
+
+	// HTTPClient is used to send requests. When nil, http.DefaultClient is
+	// used. Set it to configure timeouts, proxies, TLS or a custom transport.
+	HTTPClient *http.Client
+
+	// Middleware wraps the transport of HTTPClient, outermost first. Use it
+	// to inject headers, log requests or record metrics.
+	Middleware []Middleware
+
+	// Retry enables automatic retries of transient failures. When nil every
+	// request is attempted exactly once.
+	Retry *RetryPolicy
+
+	// RateLimit throttles requests client-side. When nil requests are not
+	// rate limited.
+	RateLimit *RateLimit
+
+	// MaxInFlight caps the number of concurrent requests. A request holds its
+	// slot until the response body is closed. Zero means unlimited.
+	MaxInFlight int
+
+	// SecretProvider resolves SecretRef option values when creating or
+	// updating data sources and destinations
+	SecretProvider SecretProvider
 }
 
 // NewClient returns a *Client from a valid *Config
@@ -51,34 +79,76 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("Missing APIKey")
 	}
 
-	c := &Client{Config: config}
+	c := &Client{
+		Config:     config,
+		httpClient: newHTTPClient(config),
+		limiter:    newLimiter(config),
+	}
 	return c, nil
 }
 
 // IsStrict returns true if StrictMode is set. This currently causes
-// data_source creates/updates to fail if extraneous properties
+// data source and destination creates/updates to fail if extraneous properties
 // are present in the payload.
 func (c *Client) IsStrict() bool {
 	return c.Config.StrictMode
 }
 
-func (c *Client) doRequest(method, path, body string, query url.Values) (*http.Response, error) {
+func (c *Client) doRequest(ctx context.Context, method, path, body string, query url.Values) (*http.Response, error) {
 	requestURI := strings.TrimSuffix(c.Config.RedashURI, "/") + path
 
 	log.Debug(fmt.Sprintf("[DEBUG] %s request to %s", method, path))
 
-	response, err := func() (*http.Response, error) {
-		request, err := http.NewRequest(method, requestURI, strings.NewReader(body))
-		if err != nil {
-			return nil, err
+	var response *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		response, err = func() (*http.Response, error) {
+			request, err := http.NewRequestWithContext(ctx, method, requestURI, strings.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+
+			request.Header.Add("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Key "+c.Config.APIKey)
+			request.URL.RawQuery = query.Encode()
+
+			httpClient := c.httpClient
+			if httpClient == nil {
+				httpClient = newHTTPClient(c.Config)
+			}
+
+			release, err := c.limiter.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			response, err := httpClient.Do(request)
+			if err != nil {
+				release()
+				return nil, err
+			}
+			if c.limiter != nil {
+				response.Body = &releaseOnClose{ReadCloser: response.Body, release: release}
+			}
+
+			return response, nil
+		}()
+
+		if !c.Config.Retry.shouldRetry(ctx, attempt, method, path, response, err) {
+			break
 		}
 
-		request.Header.Add("Content-Type", "application/json")
-		request.Header.Set("Authorization", "Key "+c.Config.APIKey)
-		request.URL.RawQuery = query.Encode()
+		wait := c.Config.Retry.backoff(attempt, response)
+		if response != nil {
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
 
-		return http.DefaultClient.Do(request)
-	}()
+		log.Debug(fmt.Sprintf("[DEBUG] Retrying %s request to %s in %s (attempt %d)", method, path, wait, attempt+1))
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -89,24 +159,24 @@ func (c *Client) doRequest(method, path, body string, query url.Values) (*http.R
 		if b, err := io.ReadAll(response.Body); err == nil {
 			body = string(b)
 		}
-		return nil, fmt.Errorf("%d from %s request to %s: %s", response.StatusCode, method, requestURI, body)
+		return nil, newAPIError(response.StatusCode, method, requestURI, body)
 	}
 
 	return response, nil
 }
 
-func (c *Client) get(path string, query url.Values) (*http.Response, error) {
-	return c.doRequest(http.MethodGet, path, "", query)
+func (c *Client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	return c.doRequest(ctx, http.MethodGet, path, "", query)
 }
 
-func (c *Client) post(path string, payload string, query url.Values) (*http.Response, error) {
-	return c.doRequest(http.MethodPost, path, payload, query)
+func (c *Client) post(ctx context.Context, path string, payload string, query url.Values) (*http.Response, error) {
+	return c.doRequest(ctx, http.MethodPost, path, payload, query)
 }
 
-func (c *Client) put(path string, payload string, query url.Values) (*http.Response, error) {
-	return c.doRequest(http.MethodPut, path, payload, query)
+func (c *Client) put(ctx context.Context, path string, payload string, query url.Values) (*http.Response, error) {
+	return c.doRequest(ctx, http.MethodPut, path, payload, query)
 }
 
-func (c *Client) delete(path string, query url.Values) (*http.Response, error) {
-	return c.doRequest(http.MethodDelete, path, "", query)
+func (c *Client) delete(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	return c.doRequest(ctx, http.MethodDelete, path, "", query)
 }
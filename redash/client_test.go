@@ -14,8 +14,16 @@
 package redash
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,3 +54,304 @@ func TestNewClient(t *testing.T) {
 	assert.Nil(err)
 	assert.NotNil(c)
 }
+
+func TestClientMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next.RoundTrip(request)
+			})
+		}
+	}
+
+	c, _ := NewClient(&Config{
+		RedashURI:  "https://com.acme/",
+		APIKey:     "ApIkEyApIkEyApIkEyApIkEyApIkEy",
+		Middleware: []Middleware{trace("outer"), WithHeader("X-Tenant", "acme"), trace("inner")},
+	})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/groups/1",
+		func(request *http.Request) (*http.Response, error) {
+			assert.Equal("acme", request.Header.Get("X-Tenant"))
+			assert.Equal("Key ApIkEyApIkEyApIkEyApIkEyApIkEy", request.Header.Get("Authorization"))
+			return httpmock.NewStringResponse(200, `{"id": 1, "name": "Existing Group"}`), nil
+		})
+
+	group, err := c.GetGroup(1)
+	assert.Nil(err)
+	assert.Equal(1, group.ID)
+	assert.Equal([]string{"outer", "inner"}, calls)
+}
+
+type stubTransport struct {
+	body string
+}
+
+func (s *stubTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(strings.NewReader(s.body)),
+		Request:    request,
+	}, nil
+}
+
+func TestClientCustomHTTPClient(t *testing.T) {
+	assert := assert.New(t)
+
+	transport := &stubTransport{body: `{"id": 1, "name": "Existing User"}`}
+	httpClient := &http.Client{Transport: transport}
+
+	c, _ := NewClient(&Config{
+		RedashURI:  "https://com.acme/",
+		APIKey:     "ApIkEyApIkEyApIkEyApIkEyApIkEy",
+		HTTPClient: httpClient,
+		Middleware: []Middleware{WithHeader("X-Tenant", "acme")},
+	})
+
+	user, err := c.GetUser(1)
+	assert.Nil(err)
+	assert.Equal("Existing User", user.Name)
+
+	// the caller's client must not be mutated by the middleware chain
+	assert.Same(transport, httpClient.Transport)
+}
+
+func TestAPIError(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/404",
+		httpmock.NewStringResponder(404, `{"message": "Couldn't find resource. Please login and try again."}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/403",
+		httpmock.NewStringResponder(403, `{"message": "You don't have permission to edit this query."}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/409",
+		httpmock.NewStringResponder(409, `<html>Conflict</html>`))
+
+	_, err := c.GetQuery(404)
+	var apiError *APIError
+	assert.True(errors.As(err, &apiError))
+	assert.Equal(404, apiError.StatusCode)
+	assert.Equal(http.MethodGet, apiError.Method)
+	assert.Equal("https://com.acme/api/queries/404", apiError.URL)
+	assert.Equal("Couldn't find resource. Please login and try again.", apiError.Message)
+	assert.True(IsNotFound(err))
+	assert.False(IsForbidden(err))
+	assert.Equal(`404 from GET request to https://com.acme/api/queries/404: {"message": "Couldn't find resource. Please login and try again."}`, err.Error())
+
+	_, err = c.GetQuery(403)
+	assert.True(IsForbidden(err))
+	assert.True(errors.Is(err, ErrForbidden))
+	assert.False(IsNotFound(err))
+
+	_, err = c.UpdateQuery(409, &QueryUpdatePayload{Version: 1})
+	assert.True(IsConflict(err))
+	assert.True(errors.As(err, &apiError))
+	assert.Equal("", apiError.Message)
+	assert.Equal("<html>Conflict</html>", apiError.Body)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{
+		RedashURI: "https://com.acme/",
+		APIKey:    "ApIkEyApIkEyApIkEyApIkEyApIkEy",
+		Retry:     &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	})
+
+	// GET is retried until it succeeds
+	httpmock.RegisterResponder("GET", "https://com.acme/api/groups/1",
+		httpmock.ResponderFromMultipleResponses([]*http.Response{
+			httpmock.NewStringResponse(503, "unavailable"),
+			httpmock.NewStringResponse(502, "bad gateway"),
+			httpmock.NewStringResponse(200, `{"id": 1, "name": "Existing Group"}`),
+		}))
+	group, err := c.GetGroup(1)
+	assert.Nil(err)
+	assert.Equal(1, group.ID)
+	assert.Equal(3, httpmock.GetCallCountInfo()["GET https://com.acme/api/groups/1"])
+
+	// ... but gives up after MaxAttempts
+	httpmock.RegisterResponder("GET", "https://com.acme/api/groups/2",
+		httpmock.NewStringResponder(503, "unavailable"))
+	_, err = c.GetGroup(2)
+	var apiError *APIError
+	assert.True(errors.As(err, &apiError))
+	assert.Equal(503, apiError.StatusCode)
+	assert.Equal(3, httpmock.GetCallCountInfo()["GET https://com.acme/api/groups/2"])
+
+	// POST updating an existing query is idempotent
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5",
+		httpmock.ResponderFromMultipleResponses([]*http.Response{
+			httpmock.NewStringResponse(502, "bad gateway"),
+			httpmock.NewStringResponse(200, `{"id": 5}`),
+		}))
+	query, err := c.UpdateQuery(5, &QueryUpdatePayload{Name: "My query"})
+	assert.Nil(err)
+	assert.Equal(5, query.ID)
+	assert.Equal(2, httpmock.GetCallCountInfo()["POST https://com.acme/api/queries/5"])
+
+	// POST creating a query is not retried on a 503...
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries",
+		httpmock.NewStringResponder(503, "unavailable"))
+	_, err = c.CreateQuery(&QueryCreatePayload{Name: "My query"})
+	assert.NotNil(err)
+	assert.Equal(1, httpmock.GetCallCountInfo()["POST https://com.acme/api/queries"])
+
+	// ... but is retried when rate limited, honouring Retry-After
+	rateLimited := httpmock.NewStringResponse(429, "slow down")
+	rateLimited.Header.Set("Retry-After", "0")
+	httpmock.RegisterResponder("POST", "https://com.acme/api/groups",
+		httpmock.ResponderFromMultipleResponses([]*http.Response{
+			rateLimited,
+			httpmock.NewStringResponse(200, `{"id": 2, "name": "New Group"}`),
+		}))
+	group, err = c.CreateGroup(&GroupCreatePayload{Name: "New Group"})
+	assert.Nil(err)
+	assert.Equal(2, group.ID)
+	assert.Equal(2, httpmock.GetCallCountInfo()["POST https://com.acme/api/groups"])
+}
+
+func TestRetryPolicyReplayedDelete(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{
+		RedashURI: "https://com.acme/",
+		APIKey:    "ApIkEyApIkEyApIkEyApIkEyApIkEy",
+		Retry:     &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	})
+
+	// the job was cancelled by the first attempt but the gateway timed out,
+	// so the replay finds nothing to cancel
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/jobs/abc-123",
+		httpmock.ResponderFromMultipleResponses([]*http.Response{
+			httpmock.NewStringResponse(504, "gateway timeout"),
+			httpmock.NewStringResponse(404, `{"message": "Not found"}`),
+		}))
+
+	err := c.CancelJob("abc-123")
+	assert.True(IsNotFound(err))
+	assert.Equal(2, httpmock.GetCallCountInfo()["DELETE https://com.acme/api/jobs/abc-123"])
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	wait, ok := parseRetryAfter("120", now)
+	assert.True(ok)
+	assert.Equal(2*time.Minute, wait)
+
+	wait, ok = parseRetryAfter("Tue, 03 Jan 2023 12:00:30 GMT", now)
+	assert.True(ok)
+	assert.Equal(30*time.Second, wait)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(ok)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(ok)
+}
+
+func TestIsIdempotent(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(isIdempotent(http.MethodGet, "/api/queries"))
+	assert.True(isIdempotent(http.MethodDelete, "/api/queries/1"))
+	assert.True(isIdempotent(http.MethodPost, "/api/queries/1"))
+	assert.True(isIdempotent(http.MethodPost, "/api/widgets/42"))
+	assert.True(isIdempotent(http.MethodPost, "/api/users/3/disable"))
+	assert.True(isIdempotent(http.MethodPost, "/api/dashboards/service-slos/favorite"))
+	assert.True(isIdempotent(http.MethodPost, "/api/data_sources/2/pause"))
+	assert.False(isIdempotent(http.MethodPost, "/api/queries/1/fork"))
+	assert.False(isIdempotent(http.MethodPost, "/api/queries"))
+	assert.False(isIdempotent(http.MethodPost, "/api/groups/1/members"))
+}
+
+func TestClientMaxInFlight(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", MaxInFlight: 2})
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	httpmock.RegisterResponder("GET", `=~^https://com.acme/api/queries/\d+\z`,
+		func(request *http.Request) (*http.Response, error) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return httpmock.NewStringResponse(200, `{"id": 1}`), nil
+		})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			_, err := c.GetQuery(id)
+			assert.Nil(err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(2, maxInFlight)
+	stats := c.LimiterStats()
+	assert.Equal(0, stats.InFlight)
+	assert.Equal(0, stats.Waiting)
+	assert.Equal(int64(6), stats.TotalRequests)
+	assert.True(stats.TotalWaits > 0)
+}
+
+func TestClientRateLimit(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{
+		RedashURI: "https://com.acme/",
+		APIKey:    "ApIkEyApIkEyApIkEyApIkEyApIkEy",
+		RateLimit: &RateLimit{RequestsPerSecond: 50, Burst: 1},
+	})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/groups/1",
+		httpmock.NewStringResponder(200, `{"id": 1, "name": "Existing Group"}`))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := c.GetGroup(1)
+		assert.Nil(err)
+	}
+	assert.True(time.Since(start) >= 35*time.Millisecond)
+	assert.Equal(int64(3), c.LimiterStats().TotalRequests)
+
+	// a canceled context gives back its reservation
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.GetGroupContext(ctx, 1)
+	assert.ErrorIs(err, context.Canceled)
+	assert.Equal(int64(3), c.LimiterStats().TotalRequests)
+}
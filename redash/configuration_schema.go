@@ -0,0 +1,295 @@
+package redash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SecretMask is what Redash returns in place of secret option values. Sending
+// it back in an update keeps the stored value.
+const SecretMask = "--------"
+
+// ConfigurationSchema is the JSON schema Redash publishes for the options of
+// a data source or destination type
+type ConfigurationSchema struct {
+	Type       string                           `json:"type,omitempty"`
+	Properties map[string]ConfigurationProperty `json:"properties,omitempty"`
+	Required   []string                         `json:"required,omitempty"`
+	Order      []string                         `json:"order,omitempty"`
+	Secret     []string                         `json:"secret,omitempty"`
+}
+
+// ConfigurationProperty describes a single option of a ConfigurationSchema
+type ConfigurationProperty struct {
+	Type    string        `json:"type,omitempty"`
+	Title   string        `json:"title,omitempty"`
+	Default interface{}   `json:"default,omitempty"`
+	Enum    []interface{} `json:"enum,omitempty"`
+}
+
+// IsSecret reports whether the named option holds a secret, such as a password
+func (s *ConfigurationSchema) IsSecret(name string) bool {
+	for _, secret := range s.Secret {
+		if secret == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns the property names in the order Redash shows them, followed
+// by the properties missing from Order alphabetically
+func (s *ConfigurationSchema) fields() []string {
+	fields := []string{}
+	seen := map[string]bool{}
+	for _, name := range s.Order {
+		if _, exists := s.Properties[name]; exists && !seen[name] {
+			fields = append(fields, name)
+			seen[name] = true
+		}
+	}
+
+	rest := []string{}
+	for name := range s.Properties {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(fields, rest...)
+}
+
+// OptionError is a single problem with an option value
+type OptionError struct {
+	Field   string
+	Message string
+}
+
+func (e *OptionError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// OptionsError lists every problem found validating the options of a data
+// source or destination against the ConfigurationSchema of its type
+type OptionsError struct {
+	Type   string
+	Errors []*OptionError
+}
+
+func (e *OptionsError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("invalid options for type %s: %s", e.Type, strings.Join(messages, "; "))
+}
+
+// Unwrap returns the individual OptionErrors
+func (e *OptionsError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Is reports whether target is one of the individual OptionErrors. errors.Is
+// only follows Unwrap() []error since Go 1.20.
+func (e *OptionsError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As sets target to the first individual OptionError it matches, see Is
+func (e *OptionsError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Field returns the problem with the named option, or nil if there is none
+func (e *OptionsError) Field(name string) *OptionError {
+	for _, err := range e.Errors {
+		if err.Field == name {
+			return err
+		}
+	}
+	return nil
+}
+
+// sanitize checks options against the schema. Defaults are filled in for
+// missing options and, unless strict, unknown options are dropped with a
+// warning. All problems are returned together as an *OptionsError.
+func (s *ConfigurationSchema) sanitize(typeName string, options map[string]interface{}, strict bool) (map[string]interface{}, error) {
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	optionsErr := &OptionsError{Type: typeName}
+
+	for _, name := range s.fields() {
+		property := s.Properties[name]
+		value, exists := options[name]
+		if !exists || value == nil {
+			if property.Default != nil {
+				options[name] = property.Default
+			}
+			continue
+		}
+
+		if s.IsSecret(name) && value == SecretMask {
+			continue
+		}
+		if err := property.check(value, s.IsSecret(name)); err != nil {
+			optionsErr.Errors = append(optionsErr.Errors, &OptionError{Field: name, Message: err.Error()})
+		}
+	}
+
+	for _, name := range s.Required {
+		if value, exists := options[name]; !exists || value == nil {
+			optionsErr.Errors = append(optionsErr.Errors, &OptionError{Field: name, Message: "required option is missing"})
+		}
+	}
+
+	unknown := []string{}
+	for name := range options {
+		if _, exists := s.Properties[name]; !exists {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		if strict {
+			optionsErr.Errors = append(optionsErr.Errors, &OptionError{Field: name, Message: "unknown option"})
+			continue
+		}
+
+		log.Warn(fmt.Sprintf("[WARN] Ignoring invalid field (%s) for type: %s", name, typeName))
+		delete(options, name)
+	}
+
+	if len(optionsErr.Errors) > 0 {
+		return nil, optionsErr
+	}
+	return options, nil
+}
+
+// check validates a value against the property's type and enum. The value
+// is left out of the error for secrets.
+func (p ConfigurationProperty) check(value interface{}, secret bool) error {
+	kind := reflect.ValueOf(value).Kind()
+
+	valid := true
+	switch p.Type {
+	case "number":
+		_, valid = toFloat(value)
+	case "integer":
+		f, ok := toFloat(value)
+		valid = ok && f == math.Trunc(f)
+	case "string":
+		valid = kind == reflect.String
+	case "boolean":
+		valid = kind == reflect.Bool
+	case "array":
+		valid = kind == reflect.Slice || kind == reflect.Array
+	case "object":
+		valid = kind == reflect.Map || kind == reflect.Struct
+	}
+	if !valid {
+		return fmt.Errorf("expected %s, got %T", p.Type, value)
+	}
+
+	if len(p.Enum) == 0 {
+		return nil
+	}
+	for _, allowed := range p.Enum {
+		if optionValuesEqual(allowed, value) {
+			return nil
+		}
+	}
+	if secret {
+		return fmt.Errorf("value is not one of the allowed values")
+	}
+	return fmt.Errorf("%v is not one of %v", value, p.Enum)
+}
+
+// toFloat converts any Go or JSON number to a float64
+func toFloat(value interface{}) (float64, bool) {
+	if number, ok := value.(json.Number); ok {
+		f, err := number.Float64()
+		return f, err == nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// optionValuesEqual compares values regardless of their numeric Go type, as
+// enums decoded from JSON only hold float64s
+func optionValuesEqual(a, b interface{}) bool {
+	fa, aIsNumber := toFloat(a)
+	fb, bIsNumber := toFloat(b)
+	if aIsNumber || bIsNumber {
+		return aIsNumber && bIsNumber && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// structToOptions converts a typed options struct to an options map through
+// its JSON encoding
+func structToOptions(v interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	options := map[string]interface{}{}
+	err = json.Unmarshal(encoded, &options)
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// optionsToStruct fills a typed options struct from an options map. Options
+// the struct doesn't know about are ignored.
+func optionsToStruct(options map[string]interface{}, v interface{}) error {
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
+
+// validate checks options against the schema, like sanitize in strict mode,
+// without modifying them
+func (s *ConfigurationSchema) validate(typeName string, options map[string]interface{}) error {
+	copied := make(map[string]interface{}, len(options))
+	for name, value := range options {
+		copied[name] = value
+	}
+	_, err := s.sanitize(typeName, copied, true)
+	return err
+}
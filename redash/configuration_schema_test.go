@@ -0,0 +1,153 @@
+package redash
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const pgConfigurationSchema = `{
+	"type": "object",
+	"properties": {
+		"host": {"type": "string", "default": "127.0.0.1"},
+		"port": {"type": "number", "default": 5432},
+		"user": {"type": "string"},
+		"password": {"type": "string"},
+		"dbname": {"type": "string", "title": "Database Name"},
+		"sslmode": {"type": "string", "title": "SSL Mode", "default": "prefer", "enum": ["disable", "allow", "prefer", "require"]},
+		"retries": {"type": "integer"},
+		"schemas": {"type": "array"},
+		"extra": {"type": "object"},
+		"debug": {"type": "boolean"}
+	},
+	"order": ["host", "port", "user", "password"],
+	"required": ["dbname"],
+	"secret": ["password"]
+}`
+
+func testConfigurationSchema(t *testing.T) *ConfigurationSchema {
+	schema := new(ConfigurationSchema)
+	if err := json.Unmarshal([]byte(pgConfigurationSchema), schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestConfigurationSchemaSanitize(t *testing.T) {
+	assert := assert.New(t)
+	schema := testConfigurationSchema(t)
+
+	options, err := schema.sanitize("pg", map[string]interface{}{
+		"dbname":   "analytics",
+		"port":     float64(5433),
+		"retries":  json.Number("3"),
+		"password": SecretMask,
+		"schemas":  []interface{}{"public"},
+		"extra":    map[string]interface{}{"a": 1},
+		"debug":    true,
+		"unknown":  "dropped",
+	}, false)
+	assert.Nil(err)
+	assert.Equal(map[string]interface{}{
+		"host":     "127.0.0.1",
+		"port":     float64(5433),
+		"dbname":   "analytics",
+		"sslmode":  "prefer",
+		"retries":  json.Number("3"),
+		"password": SecretMask,
+		"schemas":  []interface{}{"public"},
+		"extra":    map[string]interface{}{"a": 1},
+		"debug":    true,
+	}, options)
+
+	options, err = schema.sanitize("pg", nil, false)
+	assert.Nil(options)
+	assert.EqualError(err, "invalid options for type pg: dbname: required option is missing")
+}
+
+func TestConfigurationSchemaSanitizeErrors(t *testing.T) {
+	assert := assert.New(t)
+	schema := testConfigurationSchema(t)
+
+	_, err := schema.sanitize("pg", map[string]interface{}{
+		"port":    "5432",
+		"user":    1,
+		"sslmode": "verify-full",
+		"retries": 2.5,
+		"debug":   "yes",
+		"unknown": "rejected",
+	}, true)
+
+	var optionsErr *OptionsError
+	assert.True(errors.As(err, &optionsErr))
+	assert.Equal("pg", optionsErr.Type)
+	assert.Equal([]*OptionError{
+		{Field: "port", Message: "expected number, got string"},
+		{Field: "user", Message: "expected string, got int"},
+		{Field: "debug", Message: "expected boolean, got string"},
+		{Field: "retries", Message: "expected integer, got float64"},
+		{Field: "sslmode", Message: "verify-full is not one of [disable allow prefer require]"},
+		{Field: "dbname", Message: "required option is missing"},
+		{Field: "unknown", Message: "unknown option"},
+	}, optionsErr.Errors)
+	assert.Equal("required option is missing", optionsErr.Field("dbname").Message)
+	assert.Nil(optionsErr.Field("host"))
+	assert.True(errors.Is(err, optionsErr.Errors[0]))
+
+	// without relying on Go 1.20 multi-error unwrapping
+	assert.True(optionsErr.Is(optionsErr.Errors[1]))
+	assert.False(optionsErr.Is(errors.New("port: expected number, got string")))
+	var optionErr *OptionError
+	assert.True(optionsErr.As(&optionErr))
+	assert.Equal("port", optionErr.Field)
+}
+
+func TestSanitizeDataSourceOptions(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/types",
+		httpmock.NewStringResponder(200, `[{"type": "pg", "name": "PostgreSQL", "configuration_schema": `+pgConfigurationSchema+`}]`))
+
+	dataSource, err := c.SanitizeDataSourceOptions(&DataSource{
+		Type:    "pg",
+		Options: map[string]interface{}{"dbname": "analytics", "port": float64(5432)},
+	})
+	assert.Nil(err)
+	assert.Equal("prefer", dataSource.Options["sslmode"])
+	assert.Equal(float64(5432), dataSource.Options["port"])
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/types",
+		httpmock.NewStringResponder(500, `{"message": "Internal Server Error"}`))
+
+	_, err = c.SanitizeDataSourceOptions(&DataSource{Type: "pg"})
+	assert.NotNil(err)
+}
+
+func TestSanitizeDestinationOptions(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy", StrictMode: true})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/destinations/types",
+		httpmock.NewStringResponder(200, `[{"type": "slack", "name": "Slack", "configuration_schema": {
+			"type": "object",
+			"properties": {"url": {"type": "string", "title": "Slack Webhook URL"}, "username": {"type": "string"}},
+			"required": ["url"],
+			"secret": ["url"]
+		}}]`))
+
+	_, err := c.SanitizeDestinationOptions(&CreateOrUpdateDestinationPayload{
+		Type:    "slack",
+		Options: map[string]interface{}{"username": 42, "channel": "#alerts"},
+	})
+	assert.EqualError(err, "invalid options for type slack: username: expected string, got int; url: required option is missing; channel: unknown option")
+}
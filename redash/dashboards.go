@@ -1,12 +1,56 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"strconv"
 	"time"
 )
 
+// DashboardList models the response from Redash's /api/dashboards endpoint
+type DashboardList struct {
+	Count    int         `json:"count"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Results  []Dashboard `json:"results"`
+}
+
+// DashboardListOptions filters and orders the dashboards returned by
+// GetDashboards and GetMyDashboards
+type DashboardListOptions struct {
+	// Search matches dashboard names
+	Search string
+	// Tags restricts the list to dashboards having all of these tags
+	Tags []string
+	// OnlyFavorites lists the dashboards favorited by the API key's user
+	OnlyFavorites bool
+	// Order is a field name such as "name" or "-created_at" for descending order
+	Order    string
+	Page     int
+	PageSize int
+}
+
+func (o *DashboardListOptions) values() url.Values {
+	queryParams := url.Values{}
+	if o == nil {
+		return queryParams
+	}
+
+	if o.Search != "" {
+		queryParams.Set("q", o.Search)
+	}
+	for _, tag := range o.Tags {
+		queryParams.Add("tags", tag)
+	}
+	if o.Order != "" {
+		queryParams.Set("order", o.Order)
+	}
+	setPageValues(queryParams, o.Page, o.PageSize)
+
+	return queryParams
+}
+
 type Dashboard struct {
 	ID                      int           `json:"id"`
 	Slug                    string        `json:"slug"`
@@ -41,14 +85,97 @@ type DashboardCreatePayload struct {
 
 type DashboardUpdatePayload struct {
 	Name string `json:"name"`
+	// Tags replaces the dashboard's tags when set, point it to an empty
+	// slice to remove them all
+	Tags *[]string `json:"tags,omitempty"`
+}
+
+// GetDashboards returns a paginated list of dashboards
+func (c *Client) GetDashboards(options *DashboardListOptions) (*DashboardList, error) {
+	return c.GetDashboardsContext(context.Background(), options)
+}
+
+// GetDashboardsContext is like GetDashboards but uses ctx for the underlying requests.
+func (c *Client) GetDashboardsContext(ctx context.Context, options *DashboardListOptions) (*DashboardList, error) {
+	path := "/api/dashboards"
+	if options != nil && options.OnlyFavorites {
+		path = "/api/dashboards/favorites"
+	}
+
+	return c.getDashboardList(ctx, path, options)
+}
+
+// GetMyDashboards returns a paginated list of the dashboards created by the
+// API key's user
+func (c *Client) GetMyDashboards(options *DashboardListOptions) (*DashboardList, error) {
+	return c.GetMyDashboardsContext(context.Background(), options)
+}
+
+// GetMyDashboardsContext is like GetMyDashboards but uses ctx for the underlying requests.
+func (c *Client) GetMyDashboardsContext(ctx context.Context, options *DashboardListOptions) (*DashboardList, error) {
+	return c.getDashboardList(ctx, "/api/dashboards/my", options)
+}
+
+// DashboardsPager iterates over every dashboard matching the options
+func (c *Client) DashboardsPager(options *DashboardListOptions) *Pager[Dashboard] {
+	return c.DashboardsPagerContext(context.Background(), options)
+}
+
+// DashboardsPagerContext is like DashboardsPager but uses ctx for the underlying requests.
+func (c *Client) DashboardsPagerContext(ctx context.Context, options *DashboardListOptions) *Pager[Dashboard] {
+	pageOptions := DashboardListOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+
+	return NewPager(ctx, pageOptions.Page, pageOptions.PageSize, func(ctx context.Context, page int) ([]Dashboard, int, error) {
+		pageOptions.Page = page
+		dashboards, err := c.GetDashboardsContext(ctx, &pageOptions)
+		if err != nil {
+			return nil, 0, err
+		}
+		return dashboards.Results, dashboards.Count, nil
+	})
+}
+
+// ListAllDashboards returns every dashboard matching the options, up to limit
+// dashboards when limit is positive
+func (c *Client) ListAllDashboards(options *DashboardListOptions, limit int) ([]Dashboard, error) {
+	return c.ListAllDashboardsContext(context.Background(), options, limit)
+}
+
+// ListAllDashboardsContext is like ListAllDashboards but uses ctx for the underlying requests.
+func (c *Client) ListAllDashboardsContext(ctx context.Context, options *DashboardListOptions, limit int) ([]Dashboard, error) {
+	return ListAll(c.DashboardsPagerContext(ctx, options), limit)
+}
+
+func (c *Client) getDashboardList(ctx context.Context, path string, options *DashboardListOptions) (*DashboardList, error) {
+	response, err := c.get(ctx, path, options.values())
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	dashboards := new(DashboardList)
+	err = json.NewDecoder(response.Body).Decode(dashboards)
+	if err != nil {
+		return nil, err
+	}
+
+	return dashboards, nil
 }
 
 // GetDashboard gets a specific dashboard
 func (c *Client) GetDashboard(slug string) (*Dashboard, error) {
+	return c.GetDashboardContext(context.Background(), slug)
+}
+
+// GetDashboardContext is like GetDashboard but uses ctx for the underlying requests.
+func (c *Client) GetDashboardContext(ctx context.Context, slug string) (*Dashboard, error) {
 	path := "/api/dashboards/" + slug
 
 	queryParams := url.Values{}
-	response, err := c.get(path, queryParams)
+	response, err := c.get(ctx, path, queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +191,11 @@ func (c *Client) GetDashboard(slug string) (*Dashboard, error) {
 }
 
 func (c *Client) CreateDashboard(dashboard *DashboardCreatePayload) (*Dashboard, error) {
+	return c.CreateDashboardContext(context.Background(), dashboard)
+}
+
+// CreateDashboardContext is like CreateDashboard but uses ctx for the underlying requests.
+func (c *Client) CreateDashboardContext(ctx context.Context, dashboard *DashboardCreatePayload) (*Dashboard, error) {
 	path := "/api/dashboards"
 
 	payload, err := json.Marshal(dashboard)
@@ -72,7 +204,7 @@ func (c *Client) CreateDashboard(dashboard *DashboardCreatePayload) (*Dashboard,
 	}
 
 	queryParams := url.Values{}
-	response, err := c.post(path, string(payload), queryParams)
+	response, err := c.post(ctx, path, string(payload), queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +220,11 @@ func (c *Client) CreateDashboard(dashboard *DashboardCreatePayload) (*Dashboard,
 }
 
 func (c *Client) UpdateDashboard(id int, dashboard *DashboardUpdatePayload) (*Dashboard, error) {
+	return c.UpdateDashboardContext(context.Background(), id, dashboard)
+}
+
+// UpdateDashboardContext is like UpdateDashboard but uses ctx for the underlying requests.
+func (c *Client) UpdateDashboardContext(ctx context.Context, id int, dashboard *DashboardUpdatePayload) (*Dashboard, error) {
 	path := "/api/dashboards/" + strconv.Itoa(id)
 
 	payload, err := json.Marshal(dashboard)
@@ -96,7 +233,7 @@ func (c *Client) UpdateDashboard(id int, dashboard *DashboardUpdatePayload) (*Da
 	}
 
 	queryParams := url.Values{}
-	response, err := c.post(path, string(payload), queryParams)
+	response, err := c.post(ctx, path, string(payload), queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -112,9 +249,54 @@ func (c *Client) UpdateDashboard(id int, dashboard *DashboardUpdatePayload) (*Da
 }
 
 func (c *Client) ArchiveDashboard(slug string) error {
+	return c.ArchiveDashboardContext(context.Background(), slug)
+}
+
+// ArchiveDashboardContext is like ArchiveDashboard but uses ctx for the underlying requests.
+func (c *Client) ArchiveDashboardContext(ctx context.Context, slug string) error {
 	path := "/api/dashboards/" + slug
 
-	_, err := c.delete(path, url.Values{})
+	response, err := c.delete(ctx, path, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// FavoriteDashboard adds a dashboard to the current user's favorites
+func (c *Client) FavoriteDashboard(slug string) error {
+	return c.FavoriteDashboardContext(context.Background(), slug)
+}
+
+// FavoriteDashboardContext is like FavoriteDashboard but uses ctx for the underlying requests.
+func (c *Client) FavoriteDashboardContext(ctx context.Context, slug string) error {
+	path := "/api/dashboards/" + slug + "/favorite"
+
+	response, err := c.post(ctx, path, "", url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// UnfavoriteDashboard removes a dashboard from the current user's favorites
+func (c *Client) UnfavoriteDashboard(slug string) error {
+	return c.UnfavoriteDashboardContext(context.Background(), slug)
+}
+
+// UnfavoriteDashboardContext is like UnfavoriteDashboard but uses ctx for the underlying requests.
+func (c *Client) UnfavoriteDashboardContext(ctx context.Context, slug string) error {
+	path := "/api/dashboards/" + slug + "/favorite"
+
+	response, err := c.delete(ctx, path, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
 
-	return err
+	return nil
 }
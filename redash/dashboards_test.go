@@ -2,6 +2,7 @@ package redash
 
 import (
 	"io/ioutil"
+	"net/http"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
@@ -52,6 +53,47 @@ func TestGetDashboard(t *testing.T) {
 	assert.Equal(1, widget.DashboardID)
 }
 
+func TestGetDashboards(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	body, err := ioutil.ReadFile("testdata/get-dashboards.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/dashboards",
+		"q=slo&tags=reliability&tags=team-a&order=-created_at&page=2&page_size=25",
+		httpmock.NewStringResponder(200, string(body)))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/dashboards/favorites", "",
+		httpmock.NewStringResponder(200, string(body)))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/dashboards/my", "page_size=10",
+		httpmock.NewStringResponder(200, string(body)))
+
+	dashboards, err := c.GetDashboards(&DashboardListOptions{
+		Search:   "slo",
+		Tags:     []string{"reliability", "team-a"},
+		Order:    "-created_at",
+		Page:     2,
+		PageSize: 25,
+	})
+	assert.Nil(err)
+	assert.Equal(2, dashboards.Count)
+	assert.Equal(1, dashboards.Page)
+	assert.Equal(25, dashboards.PageSize)
+	assert.Equal(2, len(dashboards.Results))
+	assert.Equal("service-slos", dashboards.Results[0].Slug)
+	assert.Equal([]string{"reliability", "finance"}, dashboards.Results[1].Tags)
+
+	_, err = c.GetDashboards(&DashboardListOptions{OnlyFavorites: true})
+	assert.Nil(err)
+
+	_, err = c.GetMyDashboards(&DashboardListOptions{PageSize: 10})
+	assert.Nil(err)
+}
+
 func TestCreateDashboard(t *testing.T) {
 	assert := assert.New(t)
 	httpmock.Activate()
@@ -102,6 +144,35 @@ func TestUpdateDashboard(t *testing.T) {
 	assert.Nil(dashboard.Widgets)
 }
 
+func TestUpdateDashboardTags(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	var payloads []string
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards/5",
+		func(request *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(request.Body)
+			payloads = append(payloads, string(body))
+			return httpmock.NewStringResponse(200, `{"id": 5, "name": "New Name"}`), nil
+		})
+
+	_, err := c.UpdateDashboard(5, &DashboardUpdatePayload{Name: "New Name"})
+	assert.Nil(err)
+	_, err = c.UpdateDashboard(5, &DashboardUpdatePayload{Name: "New Name", Tags: &[]string{}})
+	assert.Nil(err)
+	_, err = c.UpdateDashboard(5, &DashboardUpdatePayload{Name: "New Name", Tags: &[]string{"kpi"}})
+	assert.Nil(err)
+
+	assert.Equal([]string{
+		`{"name":"New Name"}`,
+		`{"name":"New Name","tags":[]}`,
+		`{"name":"New Name","tags":["kpi"]}`,
+	}, payloads)
+}
+
 func TestArchiveDashboard(t *testing.T) {
 	assert := assert.New(t)
 	httpmock.Activate()
@@ -115,3 +186,23 @@ func TestArchiveDashboard(t *testing.T) {
 	err := c.ArchiveDashboard("my-dashboard")
 	assert.Nil(err)
 }
+
+func TestFavoriteDashboard(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/dashboards/service-slos/favorite",
+		httpmock.NewStringResponder(200, "{}"))
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/dashboards/service-slos/favorite",
+		httpmock.NewStringResponder(200, "{}"))
+
+	assert.Nil(c.FavoriteDashboard("service-slos"))
+	assert.Nil(c.UnfavoriteDashboard("service-slos"))
+
+	info := httpmock.GetCallCountInfo()
+	assert.Equal(1, info["POST https://com.acme/api/dashboards/service-slos/favorite"])
+	assert.Equal(1, info["DELETE https://com.acme/api/dashboards/service-slos/favorite"])
+}
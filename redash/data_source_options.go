@@ -0,0 +1,307 @@
+package redash
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Data source types of common Redash query runners
+const (
+	DataSourceTypePostgreSQL    = "pg"
+	DataSourceTypeRedshift      = "redshift"
+	DataSourceTypeMySQL         = "mysql"
+	DataSourceTypeBigQuery      = "bigquery"
+	DataSourceTypeSnowflake     = "snowflake"
+	DataSourceTypeAthena        = "athena"
+	DataSourceTypeClickHouse    = "clickhouse"
+	DataSourceTypePresto        = "presto"
+	DataSourceTypeTrino         = "trino"
+	DataSourceTypeElasticsearch = "elasticsearch"
+	DataSourceTypeMongoDB       = "mongodb"
+	DataSourceTypeGoogleSheets  = "google_spreadsheets"
+)
+
+// DataSourceOptions is implemented by the typed options of each query
+// runner. They are converted to DataSource.Options through their JSON
+// encoding. Options left empty are omitted, so Redash applies its defaults.
+// To keep a secret out of the struct, leave it empty and set a SecretRef in
+// DataSource.Options after SetOptions.
+type DataSourceOptions interface {
+	// DataSourceType returns the Redash type the options are for
+	DataSourceType() string
+}
+
+// PostgreSQLOptions configures a PostgreSQL data source
+type PostgreSQLOptions struct {
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+	DBName   string `json:"dbname"`
+	SSLMode  string `json:"sslmode,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypePostgreSQL
+func (o *PostgreSQLOptions) DataSourceType() string { return DataSourceTypePostgreSQL }
+
+// RedshiftOptions configures an Amazon Redshift data source
+type RedshiftOptions struct {
+	Host                string `json:"host"`
+	Port                int    `json:"port"`
+	User                string `json:"user"`
+	Password            string `json:"password"`
+	DBName              string `json:"dbname"`
+	SSLMode             string `json:"sslmode,omitempty"`
+	AdhocQueryGroup     string `json:"adhoc_query_group,omitempty"`
+	ScheduledQueryGroup string `json:"scheduled_query_group,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypeRedshift
+func (o *RedshiftOptions) DataSourceType() string { return DataSourceTypeRedshift }
+
+// MySQLOptions configures a MySQL data source
+type MySQLOptions struct {
+	Host      string `json:"host,omitempty"`
+	Port      int    `json:"port,omitempty"`
+	User      string `json:"user,omitempty"`
+	Password  string `json:"passwd,omitempty"`
+	DB        string `json:"db"`
+	UseSSL    bool   `json:"use_ssl,omitempty"`
+	SSLCACert string `json:"ssl_cacert,omitempty"`
+	SSLCert   string `json:"ssl_cert,omitempty"`
+	SSLKey    string `json:"ssl_key,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypeMySQL
+func (o *MySQLOptions) DataSourceType() string { return DataSourceTypeMySQL }
+
+// BigQueryOptions configures a Google BigQuery data source. JSONKeyFile is
+// the base64 encoded JSON key of a service account.
+type BigQueryOptions struct {
+	ProjectID                 string `json:"projectId"`
+	JSONKeyFile               string `json:"jsonKeyFile"`
+	Location                  string `json:"location,omitempty"`
+	TotalMBytesProcessedLimit int    `json:"totalMBytesProcessedLimit,omitempty"`
+	UserDefinedFunctionURI    string `json:"userDefinedFunctionResourceUri,omitempty"`
+	UseStandardSQL            *bool  `json:"useStandardSql,omitempty"`
+	LoadSchema                bool   `json:"loadSchema,omitempty"`
+	MaximumBillingTier        int    `json:"maximumBillingTier,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypeBigQuery
+func (o *BigQueryOptions) DataSourceType() string { return DataSourceTypeBigQuery }
+
+// SnowflakeOptions configures a Snowflake data source
+type SnowflakeOptions struct {
+	Account   string `json:"account"`
+	User      string `json:"user"`
+	Password  string `json:"password"`
+	Warehouse string `json:"warehouse"`
+	Database  string `json:"database"`
+	Region    string `json:"region,omitempty"`
+	Host      string `json:"host,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypeSnowflake
+func (o *SnowflakeOptions) DataSourceType() string { return DataSourceTypeSnowflake }
+
+// AthenaOptions configures an Amazon Athena data source. Leave the access
+// keys empty to use IAMRole or the instance credentials of Redash.
+type AthenaOptions struct {
+	Region           string  `json:"region"`
+	S3StagingDir     string  `json:"s3_staging_dir"`
+	AWSAccessKey     string  `json:"aws_access_key,omitempty"`
+	AWSSecretKey     string  `json:"aws_secret_key,omitempty"`
+	Schema           string  `json:"schema,omitempty"`
+	Glue             bool    `json:"glue,omitempty"`
+	WorkGroup        string  `json:"work_group,omitempty"`
+	CostPerTB        float64 `json:"cost_per_tb,omitempty"`
+	IAMRole          string  `json:"iam_role,omitempty"`
+	ExternalID       string  `json:"external_id,omitempty"`
+	EncryptionOption string  `json:"encryption_option,omitempty"`
+	KMSKey           string  `json:"kms_key,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypeAthena
+func (o *AthenaOptions) DataSourceType() string { return DataSourceTypeAthena }
+
+// ClickHouseOptions configures a ClickHouse data source over its HTTP interface
+type ClickHouseOptions struct {
+	URL      string `json:"url,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+	DBName   string `json:"dbname"`
+	Timeout  int    `json:"timeout,omitempty"`
+	Verify   *bool  `json:"verify,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypeClickHouse
+func (o *ClickHouseOptions) DataSourceType() string { return DataSourceTypeClickHouse }
+
+// PrestoOptions configures a Presto data source
+type PrestoOptions struct {
+	Host     string `json:"host"`
+	Protocol string `json:"protocol,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Catalog  string `json:"catalog,omitempty"`
+	Schema   string `json:"schema,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypePresto
+func (o *PrestoOptions) DataSourceType() string { return DataSourceTypePresto }
+
+// TrinoOptions configures a Trino data source
+type TrinoOptions PrestoOptions
+
+// DataSourceType returns DataSourceTypeTrino
+func (o *TrinoOptions) DataSourceType() string { return DataSourceTypeTrino }
+
+// ElasticsearchOptions configures an Elasticsearch data source
+type ElasticsearchOptions struct {
+	Server            string `json:"server"`
+	BasicAuthUser     string `json:"basic_auth_user,omitempty"`
+	BasicAuthPassword string `json:"basic_auth_password,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypeElasticsearch
+func (o *ElasticsearchOptions) DataSourceType() string { return DataSourceTypeElasticsearch }
+
+// MongoDBOptions configures a MongoDB data source
+type MongoDBOptions struct {
+	ConnectionString string `json:"connectionString"`
+	DBName           string `json:"dbName"`
+	ReplicaSetName   string `json:"replicaSetName,omitempty"`
+	ReadPreference   string `json:"readPreference,omitempty"`
+	Username         string `json:"username,omitempty"`
+	Password         string `json:"password,omitempty"`
+}
+
+// DataSourceType returns DataSourceTypeMongoDB
+func (o *MongoDBOptions) DataSourceType() string { return DataSourceTypeMongoDB }
+
+// GoogleSheetsOptions configures a Google Sheets data source. JSONKeyFile is
+// the base64 encoded JSON key of a service account.
+type GoogleSheetsOptions struct {
+	JSONKeyFile string `json:"jsonKeyFile"`
+}
+
+// DataSourceType returns DataSourceTypeGoogleSheets
+func (o *GoogleSheetsOptions) DataSourceType() string { return DataSourceTypeGoogleSheets }
+
+// RawDataSourceOptions holds the options of a data source type without typed
+// options, as the plain options map
+type RawDataSourceOptions struct {
+	Type    string
+	Options map[string]interface{}
+}
+
+// DataSourceType returns the type the options are for
+func (o *RawDataSourceOptions) DataSourceType() string { return o.Type }
+
+// MarshalJSON encodes the options map
+func (o *RawDataSourceOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Options)
+}
+
+// UnmarshalJSON decodes the options map
+func (o *RawDataSourceOptions) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &o.Options)
+}
+
+// dataSourceSecretOptions mirrors the secret list of the configuration schema
+// of the data source types above, for DataSource.String which doesn't have
+// the schema at hand
+var dataSourceSecretOptions = map[string][]string{
+	DataSourceTypePostgreSQL:    {"password"},
+	DataSourceTypeRedshift:      {"password"},
+	DataSourceTypeMySQL:         {"passwd", "ssl_key"},
+	DataSourceTypeBigQuery:      {"jsonKeyFile"},
+	DataSourceTypeSnowflake:     {"password"},
+	DataSourceTypeAthena:        {"aws_secret_key"},
+	DataSourceTypeClickHouse:    {"password"},
+	DataSourceTypePresto:        {"password"},
+	DataSourceTypeTrino:         {"password"},
+	DataSourceTypeElasticsearch: {"basic_auth_password"},
+	DataSourceTypeMongoDB:       {"connectionString", "password"},
+	DataSourceTypeGoogleSheets:  {"jsonKeyFile"},
+}
+
+var (
+	dataSourceOptionsMu       sync.RWMutex
+	dataSourceOptionsRegistry = map[string]func() DataSourceOptions{
+		DataSourceTypePostgreSQL:    func() DataSourceOptions { return &PostgreSQLOptions{} },
+		DataSourceTypeRedshift:      func() DataSourceOptions { return &RedshiftOptions{} },
+		DataSourceTypeMySQL:         func() DataSourceOptions { return &MySQLOptions{} },
+		DataSourceTypeBigQuery:      func() DataSourceOptions { return &BigQueryOptions{} },
+		DataSourceTypeSnowflake:     func() DataSourceOptions { return &SnowflakeOptions{} },
+		DataSourceTypeAthena:        func() DataSourceOptions { return &AthenaOptions{} },
+		DataSourceTypeClickHouse:    func() DataSourceOptions { return &ClickHouseOptions{} },
+		DataSourceTypePresto:        func() DataSourceOptions { return &PrestoOptions{} },
+		DataSourceTypeTrino:         func() DataSourceOptions { return &TrinoOptions{} },
+		DataSourceTypeElasticsearch: func() DataSourceOptions { return &ElasticsearchOptions{} },
+		DataSourceTypeMongoDB:       func() DataSourceOptions { return &MongoDBOptions{} },
+		DataSourceTypeGoogleSheets:  func() DataSourceOptions { return &GoogleSheetsOptions{} },
+	}
+)
+
+// RegisterDataSourceOptions makes TypedOptions decode data sources of the
+// given type with the options returned by factory, such as the typed options
+// of a custom query runner. It replaces any previous registration.
+func RegisterDataSourceOptions(dataSourceType string, factory func() DataSourceOptions) {
+	dataSourceOptionsMu.Lock()
+	defer dataSourceOptionsMu.Unlock()
+
+	dataSourceOptionsRegistry[dataSourceType] = factory
+}
+
+// NewDataSource builds a DataSource, ready to be created, from typed options
+func NewDataSource(name string, options DataSourceOptions) (*DataSource, error) {
+	dataSource := &DataSource{Name: name}
+	if err := dataSource.SetOptions(options); err != nil {
+		return nil, err
+	}
+	return dataSource, nil
+}
+
+// SetOptions replaces the type and options of the DataSource with typed options
+func (d *DataSource) SetOptions(options DataSourceOptions) error {
+	optionsMap, err := structToOptions(options)
+	if err != nil {
+		return err
+	}
+
+	d.Type = options.DataSourceType()
+	d.Options = optionsMap
+	return nil
+}
+
+// DecodeOptions fills typed options from the options of the DataSource. The
+// options must be for the DataSource's type.
+func (d *DataSource) DecodeOptions(options DataSourceOptions) error {
+	if options.DataSourceType() != d.Type {
+		return fmt.Errorf("data source %d is of type %s, not %s", d.ID, d.Type, options.DataSourceType())
+	}
+	return optionsToStruct(d.Options, options)
+}
+
+// TypedOptions decodes the options of the DataSource into the typed options
+// registered for its type. Types without typed options fall back to a
+// *RawDataSourceOptions holding a copy of the options map.
+func (d *DataSource) TypedOptions() (DataSourceOptions, error) {
+	dataSourceOptionsMu.RLock()
+	factory, exists := dataSourceOptionsRegistry[d.Type]
+	dataSourceOptionsMu.RUnlock()
+
+	if !exists {
+		factory = func() DataSourceOptions { return &RawDataSourceOptions{Type: d.Type} }
+	}
+
+	options := factory()
+	if err := optionsToStruct(d.Options, options); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
@@ -0,0 +1,121 @@
+package redash
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDataSource(t *testing.T) {
+	assert := assert.New(t)
+
+	dataSource, err := NewDataSource("Warehouse", &RedshiftOptions{
+		Host:     "warehouse.acme.com",
+		Port:     5439,
+		User:     "redash",
+		Password: "hunter2",
+		DBName:   "analytics",
+	})
+	assert.Nil(err)
+	assert.Equal("redshift", dataSource.Type)
+	assert.Equal(map[string]interface{}{
+		"host":     "warehouse.acme.com",
+		"port":     5439.0,
+		"user":     "redash",
+		"password": "hunter2",
+		"dbname":   "analytics",
+	}, dataSource.Options)
+
+	standardSQL := false
+	dataSource, err = NewDataSource("BigQuery", &BigQueryOptions{ProjectID: "acme", JSONKeyFile: "a2V5", UseStandardSQL: &standardSQL})
+	assert.Nil(err)
+	assert.Equal(map[string]interface{}{"projectId": "acme", "jsonKeyFile": "a2V5", "useStandardSql": false}, dataSource.Options)
+
+	dataSource, err = NewDataSource("Trino", &TrinoOptions{Host: "trino.acme.com", Catalog: "hive"})
+	assert.Nil(err)
+	assert.Equal("trino", dataSource.Type)
+	assert.Equal(map[string]interface{}{"host": "trino.acme.com", "catalog": "hive"}, dataSource.Options)
+}
+
+func TestDataSourceTypedOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	dataSource := &DataSource{ID: 1, Type: "mysql", Options: map[string]interface{}{
+		"host":   "db.acme.com",
+		"port":   3306.0,
+		"passwd": SecretMask,
+		"db":     "shop",
+	}}
+	options, err := dataSource.TypedOptions()
+	assert.Nil(err)
+	assert.Equal(&MySQLOptions{Host: "db.acme.com", Port: 3306, Password: SecretMask, DB: "shop"}, options)
+
+	mysql := &MySQLOptions{}
+	assert.Nil(dataSource.DecodeOptions(mysql))
+	assert.Equal("shop", mysql.DB)
+	assert.EqualError(dataSource.DecodeOptions(&PostgreSQLOptions{}), "data source 1 is of type mysql, not pg")
+
+	dataSource = &DataSource{Type: "cassandra", Options: map[string]interface{}{"host": "cassandra.acme.com", "keyspace": "events"}}
+	options, err = dataSource.TypedOptions()
+	assert.Nil(err)
+	assert.Equal(&RawDataSourceOptions{Type: "cassandra", Options: dataSource.Options}, options)
+
+	copied, err := NewDataSource("Cassandra", options)
+	assert.Nil(err)
+	assert.Equal(dataSource.Type, copied.Type)
+	assert.Equal(dataSource.Options, copied.Options)
+}
+
+type cassandraOptions struct {
+	Host     string `json:"host"`
+	Keyspace string `json:"keyspace"`
+}
+
+func (o *cassandraOptions) DataSourceType() string { return "cassandra" }
+
+func TestRegisterDataSourceOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	RegisterDataSourceOptions("cassandra", func() DataSourceOptions { return &cassandraOptions{} })
+	defer func() {
+		dataSourceOptionsMu.Lock()
+		delete(dataSourceOptionsRegistry, "cassandra")
+		dataSourceOptionsMu.Unlock()
+	}()
+
+	dataSource := &DataSource{Type: "cassandra", Options: map[string]interface{}{"host": "cassandra.acme.com", "keyspace": "events"}}
+	options, err := dataSource.TypedOptions()
+	assert.Nil(err)
+	assert.Equal(&cassandraOptions{Host: "cassandra.acme.com", Keyspace: "events"}, options)
+}
+
+func TestCreateDataSourceWithTypedOptions(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/types",
+		httpmock.NewStringResponder(200, pgDataSourceTypes))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources",
+		func(request *http.Request) (*http.Response, error) {
+			payload := DataSource{}
+			assert.Nil(json.NewDecoder(request.Body).Decode(&payload))
+			assert.Equal("pg", payload.Type)
+			assert.Equal(map[string]interface{}{"host": "db.acme.com", "dbname": "analytics"}, payload.Options)
+			return httpmock.NewStringResponse(200, `{"id": 2, "name": "Analytics", "type": "pg", "options": {"host": "db.acme.com", "dbname": "analytics"}}`), nil
+		})
+
+	dataSource, err := NewDataSource("Analytics", &PostgreSQLOptions{Host: "db.acme.com", DBName: "analytics"})
+	assert.Nil(err)
+	created, err := c.CreateDataSource(dataSource)
+	assert.Nil(err)
+
+	options, err := created.TypedOptions()
+	assert.Nil(err)
+	assert.Equal(&PostgreSQLOptions{Host: "db.acme.com", DBName: "analytics"}, options)
+}
@@ -0,0 +1,253 @@
+package redash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// Schema is the list of tables, and their columns, Redash knows about for a data source
+type Schema struct {
+	Tables []SchemaTable `json:"schema"`
+}
+
+// SchemaTable is a table, or view, of a data source
+type SchemaTable struct {
+	Name    string         `json:"name"`
+	Columns []SchemaColumn `json:"columns"`
+}
+
+// SchemaColumn is a column of a SchemaTable. Type is only reported by
+// recent Redash versions and query runners that support it.
+type SchemaColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// UnmarshalJSON accepts both plain column names and {"name", "type"} objects,
+// as older Redash versions only return the names
+func (c *SchemaColumn) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*c = SchemaColumn{Name: name}
+		return nil
+	}
+
+	type column SchemaColumn
+	return json.Unmarshal(data, (*column)(c))
+}
+
+// SchemaError is returned when Redash cannot retrieve the schema of a data source
+type SchemaError struct {
+	DataSourceID int    `json:"-"`
+	Code         int    `json:"code"`
+	Message      string `json:"message"`
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("schema of data source %d: %s", e.DataSourceID, e.Message)
+}
+
+// Table returns the named table, or nil if the schema doesn't have it
+func (s *Schema) Table(name string) *SchemaTable {
+	for i := range s.Tables {
+		if s.Tables[i].Name == name {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
+// Column returns the named column of a table, or nil if either doesn't exist
+func (s *Schema) Column(table, column string) *SchemaColumn {
+	t := s.Table(table)
+	if t == nil {
+		return nil
+	}
+	return t.Column(column)
+}
+
+// Column returns the named column, or nil if the table doesn't have it
+func (t *SchemaTable) Column(name string) *SchemaColumn {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// SchemaColumnRef identifies a column within a schema
+type SchemaColumnRef struct {
+	Table  string
+	Column string
+}
+
+// SchemaColumnChange is a column whose type differs between two schemas
+type SchemaColumnChange struct {
+	SchemaColumnRef
+	OldType string
+	NewType string
+}
+
+// SchemaDiff lists the differences between two schema snapshots. Columns of
+// added or removed tables are only reported through AddedTables and
+// RemovedTables.
+type SchemaDiff struct {
+	AddedTables    []string
+	RemovedTables  []string
+	AddedColumns   []SchemaColumnRef
+	RemovedColumns []SchemaColumnRef
+	ChangedColumns []SchemaColumnChange
+}
+
+// IsEmpty reports whether both schemas were the same
+func (d *SchemaDiff) IsEmpty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 &&
+		len(d.AddedColumns) == 0 && len(d.RemovedColumns) == 0 && len(d.ChangedColumns) == 0
+}
+
+// Dropped reports whether the column is gone from the new schema, either on
+// its own or along with its table
+func (d *SchemaDiff) Dropped(table, column string) bool {
+	for _, name := range d.RemovedTables {
+		if name == table {
+			return true
+		}
+	}
+	for _, ref := range d.RemovedColumns {
+		if ref.Table == table && ref.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffSchemas compares two snapshots of a data source schema. Type changes
+// are only reported when both snapshots have a type for the column.
+func DiffSchemas(before, after *Schema) *SchemaDiff {
+	diff := &SchemaDiff{}
+
+	for _, table := range before.Tables {
+		newTable := after.Table(table.Name)
+		if newTable == nil {
+			diff.RemovedTables = append(diff.RemovedTables, table.Name)
+			continue
+		}
+
+		for _, column := range table.Columns {
+			newColumn := newTable.Column(column.Name)
+			ref := SchemaColumnRef{Table: table.Name, Column: column.Name}
+			switch {
+			case newColumn == nil:
+				diff.RemovedColumns = append(diff.RemovedColumns, ref)
+			case column.Type != "" && newColumn.Type != "" && column.Type != newColumn.Type:
+				diff.ChangedColumns = append(diff.ChangedColumns, SchemaColumnChange{
+					SchemaColumnRef: ref,
+					OldType:         column.Type,
+					NewType:         newColumn.Type,
+				})
+			}
+		}
+
+		for _, column := range newTable.Columns {
+			if table.Column(column.Name) == nil {
+				diff.AddedColumns = append(diff.AddedColumns, SchemaColumnRef{Table: table.Name, Column: column.Name})
+			}
+		}
+	}
+
+	for _, table := range after.Tables {
+		if before.Table(table.Name) == nil {
+			diff.AddedTables = append(diff.AddedTables, table.Name)
+		}
+	}
+
+	sort.Strings(diff.AddedTables)
+	sort.Strings(diff.RemovedTables)
+	sortColumnRefs(diff.AddedColumns)
+	sortColumnRefs(diff.RemovedColumns)
+	sort.Slice(diff.ChangedColumns, func(i, j int) bool {
+		return columnRefLess(diff.ChangedColumns[i].SchemaColumnRef, diff.ChangedColumns[j].SchemaColumnRef)
+	})
+
+	return diff
+}
+
+func sortColumnRefs(refs []SchemaColumnRef) {
+	sort.Slice(refs, func(i, j int) bool { return columnRefLess(refs[i], refs[j]) })
+}
+
+func columnRefLess(a, b SchemaColumnRef) bool {
+	if a.Table != b.Table {
+		return a.Table < b.Table
+	}
+	return a.Column < b.Column
+}
+
+// GetDataSourceSchema gets the schema of a data source. With refresh the
+// schema is fetched again from the data source instead of Redash's cache.
+func (c *Client) GetDataSourceSchema(id int, refresh bool) (*Schema, error) {
+	return c.GetDataSourceSchemaContext(context.Background(), id, refresh)
+}
+
+// GetDataSourceSchemaContext is like GetDataSourceSchema but uses ctx for the underlying requests.
+func (c *Client) GetDataSourceSchemaContext(ctx context.Context, id int, refresh bool) (*Schema, error) {
+	query := url.Values{}
+	if refresh {
+		query.Set("refresh", "true")
+	}
+
+	response, err := c.getDataSourceSchema(ctx, id, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Job != nil {
+		// Redash 10+ refreshes the schema in the background, once the job is
+		// done the cached schema is up to date
+		if _, err := c.WaitForJobContext(ctx, response.Job.ID, 0); err != nil {
+			return nil, err
+		}
+		response, err = c.getDataSourceSchema(ctx, id, url.Values{})
+		if err != nil {
+			return nil, err
+		}
+		if response.Job != nil {
+			return nil, fmt.Errorf("schema of data source %d is still being refreshed", id)
+		}
+	}
+
+	return &Schema{Tables: response.Schema}, nil
+}
+
+type dataSourceSchemaResponse struct {
+	Schema []SchemaTable `json:"schema"`
+	Job    *Job          `json:"job"`
+	Error  *SchemaError  `json:"error"`
+}
+
+func (c *Client) getDataSourceSchema(ctx context.Context, id int, query url.Values) (*dataSourceSchemaResponse, error) {
+	path := "/api/data_sources/" + strconv.Itoa(id) + "/schema"
+
+	response, err := c.get(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	schemaResponse := new(dataSourceSchemaResponse)
+	err = json.NewDecoder(response.Body).Decode(schemaResponse)
+	if err != nil {
+		return nil, err
+	}
+	if schemaResponse.Error != nil {
+		schemaResponse.Error.DataSourceID = id
+		return nil, schemaResponse.Error
+	}
+
+	return schemaResponse, nil
+}
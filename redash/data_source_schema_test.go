@@ -0,0 +1,94 @@
+package redash
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDataSourceSchema(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/1/schema",
+		httpmock.NewStringResponder(200, `{"schema": [
+			{"name": "public.users", "columns": [{"name": "id", "type": "integer"}, {"name": "email", "type": "text"}]},
+			{"name": "public.events", "columns": ["id", "user_id"]}
+		]}`))
+
+	schema, err := c.GetDataSourceSchema(1, false)
+	assert.Nil(err)
+	assert.Equal(2, len(schema.Tables))
+	assert.Equal(&SchemaColumn{Name: "email", Type: "text"}, schema.Column("public.users", "email"))
+	assert.Equal(&SchemaColumn{Name: "user_id"}, schema.Column("public.events", "user_id"))
+	assert.Nil(schema.Column("public.events", "email"))
+	assert.Nil(schema.Table("public.orders"))
+}
+
+func TestGetDataSourceSchemaRefresh(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/data_sources/1/schema", "refresh=true",
+		httpmock.NewStringResponder(200, `{"job": {"id": "schema-job", "status": 1}}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/schema-job",
+		httpmock.NewStringResponder(200, `{"job": {"id": "schema-job", "status": 3}}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/1/schema",
+		httpmock.NewStringResponder(200, `{"schema": [{"name": "users", "columns": ["id"]}]}`))
+
+	schema, err := c.GetDataSourceSchema(1, true)
+	assert.Nil(err)
+	assert.Equal([]SchemaTable{{Name: "users", Columns: []SchemaColumn{{Name: "id"}}}}, schema.Tables)
+}
+
+func TestGetDataSourceSchemaError(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/data_sources/1/schema",
+		httpmock.NewStringResponder(200, `{"error": {"code": 1, "message": "Data source type does not support retrieving schema"}}`))
+
+	_, err := c.GetDataSourceSchema(1, false)
+	var schemaErr *SchemaError
+	assert.True(errors.As(err, &schemaErr))
+	assert.Equal(1, schemaErr.Code)
+	assert.Equal("schema of data source 1: Data source type does not support retrieving schema", err.Error())
+}
+
+func TestDiffSchemas(t *testing.T) {
+	assert := assert.New(t)
+
+	before := &Schema{Tables: []SchemaTable{
+		{Name: "users", Columns: []SchemaColumn{{Name: "id", Type: "integer"}, {Name: "email", Type: "text"}, {Name: "age", Type: "integer"}}},
+		{Name: "legacy", Columns: []SchemaColumn{{Name: "id"}}},
+	}}
+	after := &Schema{Tables: []SchemaTable{
+		{Name: "users", Columns: []SchemaColumn{{Name: "id", Type: "bigint"}, {Name: "age"}, {Name: "name", Type: "text"}}},
+		{Name: "orders", Columns: []SchemaColumn{{Name: "id"}}},
+	}}
+
+	diff := DiffSchemas(before, after)
+	assert.False(diff.IsEmpty())
+	assert.Equal([]string{"orders"}, diff.AddedTables)
+	assert.Equal([]string{"legacy"}, diff.RemovedTables)
+	assert.Equal([]SchemaColumnRef{{Table: "users", Column: "name"}}, diff.AddedColumns)
+	assert.Equal([]SchemaColumnRef{{Table: "users", Column: "email"}}, diff.RemovedColumns)
+	assert.Equal([]SchemaColumnChange{{SchemaColumnRef: SchemaColumnRef{Table: "users", Column: "id"}, OldType: "integer", NewType: "bigint"}}, diff.ChangedColumns)
+
+	assert.True(diff.Dropped("users", "email"))
+	assert.True(diff.Dropped("legacy", "id"))
+	assert.False(diff.Dropped("users", "age"))
+
+	assert.True(DiffSchemas(after, after).IsEmpty())
+}
@@ -14,13 +14,12 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/url"
 	"strconv"
-
-	log "github.com/sirupsen/logrus"
 )
 
 // DataSource struct
@@ -37,31 +36,46 @@ type DataSource struct {
 	Groups             map[int]bool           `json:"groups,omitempty"`
 }
 
+// String formats the DataSource with the secrets of its type, and any option
+// named like a credential, redacted
+func (d DataSource) String() string {
+	type dataSource DataSource
+	redacted := dataSource(d)
+	redacted.Options = redactTypedOptions(d.Options, dataSourceSecretOptions[d.Type])
+	return fmt.Sprintf("%+v", redacted)
+}
+
+// IsPaused reports whether Redash has stopped running queries against the DataSource
+func (d *DataSource) IsPaused() bool {
+	return d.Paused != 0
+}
+
+// DataSourceTestResult is the outcome of a DataSource connection test
+type DataSourceTestResult struct {
+	Ok      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
 // DataSourceType struct
 type DataSourceType struct {
-	Type                string `json:"type"`
-	Name                string `json:"name,omitempty"`
-	ConfigurationSchema struct {
-		Secret     []string                               `json:"secret,omitempty"`
-		Required   []string                               `json:"required,omitempty"`
-		Type       string                                 `json:"type,omitempty"`
-		Order      []string                               `json:"order,omitempty"`
-		Properties map[string]DataSourceTypePropertyField `json:"properties,omitempty"`
-	} `json:"configuration_schema,omitempty"`
+	Type                string              `json:"type"`
+	Name                string              `json:"name,omitempty"`
+	ConfigurationSchema ConfigurationSchema `json:"configuration_schema,omitempty"`
 }
 
 // DataSourceTypePropertyField struct
-type DataSourceTypePropertyField struct {
-	Type    string
-	Title   string
-	Default interface{}
-}
+type DataSourceTypePropertyField = ConfigurationProperty
 
-//GetDataSources gets an array of all DataSources available
+// GetDataSources gets an array of all DataSources available
 func (c *Client) GetDataSources() (*[]DataSource, error) {
+	return c.GetDataSourcesContext(context.Background())
+}
+
+// GetDataSourcesContext is like GetDataSources but uses ctx for the underlying requests.
+func (c *Client) GetDataSourcesContext(ctx context.Context) (*[]DataSource, error) {
 	path := "/api/data_sources"
 	query := url.Values{}
-	response, err := c.get(path, query)
+	response, err := c.get(ctx, path, query)
 
 	if err != nil {
 		return nil, err
@@ -78,11 +92,16 @@ func (c *Client) GetDataSources() (*[]DataSource, error) {
 	return &dataSources, nil
 }
 
-//GetDataSource gets a specific DataSource
+// GetDataSource gets a specific DataSource
 func (c *Client) GetDataSource(id int) (*DataSource, error) {
+	return c.GetDataSourceContext(context.Background(), id)
+}
+
+// GetDataSourceContext is like GetDataSource but uses ctx for the underlying requests.
+func (c *Client) GetDataSourceContext(ctx context.Context, id int) (*DataSource, error) {
 	path := "/api/data_sources/" + strconv.Itoa(id)
 	query := url.Values{}
-	response, err := c.get(path, query)
+	response, err := c.get(ctx, path, query)
 	if err != nil {
 		return nil, err
 	}
@@ -103,11 +122,16 @@ func (c *Client) GetDataSource(id int) (*DataSource, error) {
 	return &dataSource, nil
 }
 
-//GetDataSourceTypes gets all available types with configuration details
+// GetDataSourceTypes gets all available types with configuration details
 func (c *Client) GetDataSourceTypes() ([]DataSourceType, error) {
+	return c.GetDataSourceTypesContext(context.Background())
+}
+
+// GetDataSourceTypesContext is like GetDataSourceTypes but uses ctx for the underlying requests.
+func (c *Client) GetDataSourceTypesContext(ctx context.Context) ([]DataSourceType, error) {
 	path := "/api/data_sources/types"
 	query := url.Values{}
-	response, err := c.get(path, query)
+	response, err := c.get(ctx, path, query)
 
 	if err != nil {
 		return nil, err
@@ -125,66 +149,51 @@ func (c *Client) GetDataSourceTypes() ([]DataSourceType, error) {
 }
 
 // SanitizeDataSourceOptions checks the validity of the options field in a
-// DataSource.Option against Redash's API and cleans up when possible
+// DataSource.Option against Redash's API and cleans up when possible. Missing
+// options with a default are filled in, and all problems are reported at once
+// as an *OptionsError.
 func (c *Client) SanitizeDataSourceOptions(dataSource *DataSource) (*DataSource, error) {
-	dataSourceTypes, err := c.GetDataSourceTypes()
+	return c.SanitizeDataSourceOptionsContext(context.Background(), dataSource)
+}
+
+// SanitizeDataSourceOptionsContext is like SanitizeDataSourceOptions but uses ctx for the underlying requests.
+func (c *Client) SanitizeDataSourceOptionsContext(ctx context.Context, dataSource *DataSource) (*DataSource, error) {
+	dataSourceTypes, err := c.GetDataSourceTypesContext(ctx)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
 
 	for _, dst := range dataSourceTypes {
 		if dst.Type == dataSource.Type {
-
-			for _, required := range dst.ConfigurationSchema.Required {
-				// does dataSource.Options have everything in configuration_schema.required[] ?
-				_, exists := dataSource.Options[required]
-				if !exists {
-					return nil, fmt.Errorf("Required field missing: " + required)
-				}
-			}
-
-			for propName, propVal := range dataSource.Options {
-				// does dataSource.Options only have what's in configuration_schema.properties[]?
-				_, exists := dst.ConfigurationSchema.Properties[propName]
-				if !exists {
-					if c.IsStrict() {
-						return nil, fmt.Errorf("Invalid field (%s) for type: %s", propName, dataSource.Type)
-					}
-
-					log.Warn(fmt.Sprintf("[WARN] Ignoring invalid field (%s) for type: %s", propName, dataSource.Type))
-					delete((*dataSource).Options, propName)
-					continue
-				}
-
-				// is the input value a valid data type?
-				switch propVal.(type) {
-				case int:
-					if dst.ConfigurationSchema.Properties[propName].Type != "number" {
-						return nil, fmt.Errorf("Invalid value type for %s", propName)
-					}
-				case string:
-					if dst.ConfigurationSchema.Properties[propName].Type != "string" {
-						return nil, fmt.Errorf("Invalid value type for %s", propName)
-					}
-				case bool:
-					if dst.ConfigurationSchema.Properties[propName].Type != "boolean" {
-						return nil, fmt.Errorf("Invalid value type for %s", propName)
-					}
-				default:
-					return nil, fmt.Errorf("Invalid value type for %s", propName)
-				}
+			options, err := dst.ConfigurationSchema.sanitize(dataSource.Type, dataSource.Options, c.IsStrict())
+			if err != nil {
+				return nil, err
 			}
+			dataSource.Options = options
 		}
 	}
 
 	return dataSource, nil
 }
 
-//CreateDataSource creates a new DataSource
+// CreateDataSource creates a new DataSource
 func (c *Client) CreateDataSource(dataSourcePayload *DataSource) (*DataSource, error) {
+	return c.CreateDataSourceContext(context.Background(), dataSourcePayload)
+}
+
+// CreateDataSourceContext is like CreateDataSource but uses ctx for the underlying requests.
+func (c *Client) CreateDataSourceContext(ctx context.Context, dataSourcePayload *DataSource) (*DataSource, error) {
 	path := "/api/data_sources"
 
-	dataSourcePayload, err := c.SanitizeDataSourceOptions(dataSourcePayload)
+	dataSourcePayload, err := c.prepareDataSource(ctx, dataSourcePayload)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMaskedSecrets(dataSourcePayload.Options); err != nil {
+		return nil, err
+	}
+
+	dataSourcePayload, err = c.SanitizeDataSourceOptionsContext(ctx, dataSourcePayload)
 	if err != nil {
 		return nil, err
 	}
@@ -195,7 +204,7 @@ func (c *Client) CreateDataSource(dataSourcePayload *DataSource) (*DataSource, e
 	}
 
 	query := url.Values{}
-	response, err := c.post(path, string(payload), query)
+	response, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return nil, err
 	}
@@ -216,11 +225,21 @@ func (c *Client) CreateDataSource(dataSourcePayload *DataSource) (*DataSource, e
 	return &dataSource, nil
 }
 
-//UpdateDataSource Updates an existing DataSource
+// UpdateDataSource Updates an existing DataSource
 func (c *Client) UpdateDataSource(id int, dataSourcePayload *DataSource) (*DataSource, error) {
+	return c.UpdateDataSourceContext(context.Background(), id, dataSourcePayload)
+}
+
+// UpdateDataSourceContext is like UpdateDataSource but uses ctx for the underlying requests.
+func (c *Client) UpdateDataSourceContext(ctx context.Context, id int, dataSourcePayload *DataSource) (*DataSource, error) {
 	path := "/api/data_sources/" + strconv.Itoa(id)
 
-	dataSourcePayload, err := c.SanitizeDataSourceOptions(dataSourcePayload)
+	dataSourcePayload, err := c.prepareDataSource(ctx, dataSourcePayload)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSourcePayload, err = c.SanitizeDataSourceOptionsContext(ctx, dataSourcePayload)
 	if err != nil {
 		return nil, err
 	}
@@ -231,7 +250,7 @@ func (c *Client) UpdateDataSource(id int, dataSourcePayload *DataSource) (*DataS
 	}
 
 	query := url.Values{}
-	response, err := c.post(path, string(payload), query)
+	response, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return nil, err
 	}
@@ -252,15 +271,176 @@ func (c *Client) UpdateDataSource(id int, dataSourcePayload *DataSource) (*DataS
 	return &dataSource, nil
 }
 
-//DeleteDataSource deletes a specific DataSource
+// prepareDataSource returns a copy of the payload with its SecretRefs resolved
+func (c *Client) prepareDataSource(ctx context.Context, dataSourcePayload *DataSource) (*DataSource, error) {
+	options, err := c.resolveSecrets(ctx, dataSourcePayload.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared := *dataSourcePayload
+	prepared.Options = options
+	return &prepared, nil
+}
+
+// PatchDataSource applies changes to an existing DataSource. Options missing
+// from the changes keep their current value, nil values remove an option and
+// masked secrets, as returned by GetDataSource, are left as stored. No update
+// is sent when nothing would change, including when changes is nil.
+//
+// Redash drops options missing from an update, so secrets that are kept are
+// sent back as the SecretMask placeholder, which Redash replaces with the
+// stored value. Masked values are never sent for options that don't already
+// hold a secret.
+func (c *Client) PatchDataSource(id int, changes *DataSource) (*DataSource, error) {
+	return c.PatchDataSourceContext(context.Background(), id, changes)
+}
+
+// PatchDataSourceContext is like PatchDataSource but uses ctx for the underlying requests.
+func (c *Client) PatchDataSourceContext(ctx context.Context, id int, changes *DataSource) (*DataSource, error) {
+	if changes == nil {
+		changes = &DataSource{}
+	}
+
+	current, err := c.GetDataSourceContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := &DataSource{
+		Name:    current.Name,
+		Type:    current.Type,
+		Options: mergeOptions(current.Options, changes.Options),
+	}
+	if changes.Name != "" {
+		updated.Name = changes.Name
+	}
+	if changes.Type != "" {
+		updated.Type = changes.Type
+	}
+
+	if updated.Name == current.Name && updated.Type == current.Type && optionsEqual(updated.Options, current.Options) {
+		return current, nil
+	}
+
+	return c.UpdateDataSourceContext(ctx, id, updated)
+}
+
+// DeleteDataSource deletes a specific DataSource
 func (c *Client) DeleteDataSource(id int) error {
+	return c.DeleteDataSourceContext(context.Background(), id)
+}
+
+// DeleteDataSourceContext is like DeleteDataSource but uses ctx for the underlying requests.
+func (c *Client) DeleteDataSourceContext(ctx context.Context, id int) error {
 	path := "/api/data_sources/" + strconv.Itoa(id)
 
 	query := url.Values{}
-	_, err := c.delete(path, query)
+	response, err := c.delete(ctx, path, query)
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 
 	return nil
 }
+
+// TestDataSource checks Redash can connect to a specific DataSource. A failed
+// connection is reported through the result, not as an error.
+func (c *Client) TestDataSource(id int) (*DataSourceTestResult, error) {
+	return c.TestDataSourceContext(context.Background(), id)
+}
+
+// TestDataSourceContext is like TestDataSource but uses ctx for the underlying requests.
+func (c *Client) TestDataSourceContext(ctx context.Context, id int) (*DataSourceTestResult, error) {
+	path := "/api/data_sources/" + strconv.Itoa(id) + "/test"
+
+	query := url.Values{}
+	response, err := c.post(ctx, path, "", query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := DataSourceTestResult{}
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PauseDataSource stops Redash from running queries against a specific
+// DataSource, queued and scheduled executions are skipped until it's resumed
+func (c *Client) PauseDataSource(id int, reason string) (*DataSource, error) {
+	return c.PauseDataSourceContext(context.Background(), id, reason)
+}
+
+// PauseDataSourceContext is like PauseDataSource but uses ctx for the underlying requests.
+func (c *Client) PauseDataSourceContext(ctx context.Context, id int, reason string) (*DataSource, error) {
+	path := "/api/data_sources/" + strconv.Itoa(id) + "/pause"
+
+	payload, err := json.Marshal(map[string]string{"reason": reason})
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	response, err := c.post(ctx, path, string(payload), query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSource := DataSource{}
+
+	err = json.Unmarshal(body, &dataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dataSource, nil
+}
+
+// ResumeDataSource lets Redash run queries against a paused DataSource again
+func (c *Client) ResumeDataSource(id int) (*DataSource, error) {
+	return c.ResumeDataSourceContext(context.Background(), id)
+}
+
+// ResumeDataSourceContext is like ResumeDataSource but uses ctx for the underlying requests.
+func (c *Client) ResumeDataSourceContext(ctx context.Context, id int) (*DataSource, error) {
+	path := "/api/data_sources/" + strconv.Itoa(id) + "/pause"
+
+	query := url.Values{}
+	response, err := c.delete(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSource := DataSource{}
+
+	err = json.Unmarshal(body, &dataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dataSource, nil
+}
@@ -0,0 +1,61 @@
+package redash
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestDataSource(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources/1/test",
+		httpmock.NewStringResponder(200, `{"message": "success", "ok": true}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources/2/test",
+		httpmock.NewStringResponder(200, `{"message": "could not connect to server: Connection refused", "ok": false}`))
+
+	result, err := c.TestDataSource(1)
+	assert.Nil(err)
+	assert.True(result.Ok)
+	assert.Equal("success", result.Message)
+
+	result, err = c.TestDataSource(2)
+	assert.Nil(err)
+	assert.False(result.Ok)
+	assert.Equal("could not connect to server: Connection refused", result.Message)
+}
+
+func TestPauseDataSource(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/data_sources/1/pause",
+		func(request *http.Request) (*http.Response, error) {
+			payload := map[string]string{}
+			assert.Nil(json.NewDecoder(request.Body).Decode(&payload))
+			assert.Equal("Warehouse maintenance", payload["reason"])
+			return httpmock.NewStringResponse(200, `{"id": 1, "name": "Warehouse", "type": "pg", "paused": 1, "pause_reason": "Warehouse maintenance"}`), nil
+		})
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/data_sources/1/pause",
+		httpmock.NewStringResponder(200, `{"id": 1, "name": "Warehouse", "type": "pg", "paused": 0, "pause_reason": null}`))
+
+	dataSource, err := c.PauseDataSource(1, "Warehouse maintenance")
+	assert.Nil(err)
+	assert.True(dataSource.IsPaused())
+	assert.Equal("Warehouse maintenance", dataSource.PauseReason)
+
+	dataSource, err = c.ResumeDataSource(1)
+	assert.Nil(err)
+	assert.False(dataSource.IsPaused())
+	assert.Equal("", dataSource.PauseReason)
+}
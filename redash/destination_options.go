@@ -0,0 +1,224 @@
+package redash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Destination types built into Redash
+const (
+	DestinationTypeEmail          = "email"
+	DestinationTypeSlack          = "slack"
+	DestinationTypeWebhook        = "webhook"
+	DestinationTypePagerDuty      = "pagerduty"
+	DestinationTypeMattermost     = "mattermost"
+	DestinationTypeMicrosoftTeams = "microsoft_teams_webhook"
+	DestinationTypeGoogleChat     = "hangouts_chat"
+	DestinationTypeDiscord        = "discord"
+	DestinationTypeChatwork       = "chatwork"
+	DestinationTypeHipChat        = "hipchat"
+)
+
+// DestinationOptions is implemented by the typed options of each destination
+// type. They are converted to the options map of a destination through their
+// JSON encoding.
+type DestinationOptions interface {
+	// DestinationType returns the Redash type the options are for
+	DestinationType() string
+}
+
+// EmailAddresses is a list of email addresses, stored by Redash as a single
+// comma separated string
+type EmailAddresses []string
+
+// MarshalJSON encodes the addresses as a comma separated string
+func (a EmailAddresses) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(a, ","))
+}
+
+// UnmarshalJSON decodes a comma separated string of addresses
+func (a *EmailAddresses) UnmarshalJSON(data []byte) error {
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return err
+	}
+
+	*a = nil
+	for _, address := range strings.Split(joined, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			*a = append(*a, address)
+		}
+	}
+	return nil
+}
+
+// EmailDestinationOptions configures an email destination
+type EmailDestinationOptions struct {
+	Addresses       EmailAddresses `json:"addresses"`
+	SubjectTemplate string         `json:"subject_template,omitempty"`
+}
+
+// DestinationType returns DestinationTypeEmail
+func (o *EmailDestinationOptions) DestinationType() string { return DestinationTypeEmail }
+
+// SlackDestinationOptions configures a Slack incoming webhook
+type SlackDestinationOptions struct {
+	URL       string `json:"url"`
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+	IconURL   string `json:"icon_url,omitempty"`
+	Channel   string `json:"channel,omitempty"`
+}
+
+// DestinationType returns DestinationTypeSlack
+func (o *SlackDestinationOptions) DestinationType() string { return DestinationTypeSlack }
+
+// WebhookDestinationOptions configures a generic webhook, optionally with basic auth
+type WebhookDestinationOptions struct {
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// DestinationType returns DestinationTypeWebhook
+func (o *WebhookDestinationOptions) DestinationType() string { return DestinationTypeWebhook }
+
+// PagerDutyDestinationOptions configures a PagerDuty service integration
+type PagerDutyDestinationOptions struct {
+	IntegrationKey string `json:"integration_key"`
+	Description    string `json:"description,omitempty"`
+}
+
+// DestinationType returns DestinationTypePagerDuty
+func (o *PagerDutyDestinationOptions) DestinationType() string { return DestinationTypePagerDuty }
+
+// MattermostDestinationOptions configures a Mattermost incoming webhook
+type MattermostDestinationOptions struct {
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	IconURL  string `json:"icon_url,omitempty"`
+	Channel  string `json:"channel,omitempty"`
+}
+
+// DestinationType returns DestinationTypeMattermost
+func (o *MattermostDestinationOptions) DestinationType() string { return DestinationTypeMattermost }
+
+// MicrosoftTeamsDestinationOptions configures a Microsoft Teams incoming webhook
+type MicrosoftTeamsDestinationOptions struct {
+	URL             string `json:"url"`
+	MessageTemplate string `json:"message_template,omitempty"`
+}
+
+// DestinationType returns DestinationTypeMicrosoftTeams
+func (o *MicrosoftTeamsDestinationOptions) DestinationType() string {
+	return DestinationTypeMicrosoftTeams
+}
+
+// GoogleChatDestinationOptions configures a Google Chat (formerly Hangouts Chat) webhook
+type GoogleChatDestinationOptions struct {
+	URL     string `json:"url"`
+	IconURL string `json:"icon_url,omitempty"`
+}
+
+// DestinationType returns DestinationTypeGoogleChat
+func (o *GoogleChatDestinationOptions) DestinationType() string { return DestinationTypeGoogleChat }
+
+// DiscordDestinationOptions configures a Discord webhook
+type DiscordDestinationOptions struct {
+	URL string `json:"url"`
+}
+
+// DestinationType returns DestinationTypeDiscord
+func (o *DiscordDestinationOptions) DestinationType() string { return DestinationTypeDiscord }
+
+// ChatworkDestinationOptions configures a Chatwork room
+type ChatworkDestinationOptions struct {
+	APIToken        string `json:"api_token"`
+	RoomID          string `json:"room_id"`
+	MessageTemplate string `json:"message_template,omitempty"`
+}
+
+// DestinationType returns DestinationTypeChatwork
+func (o *ChatworkDestinationOptions) DestinationType() string { return DestinationTypeChatwork }
+
+// HipChatDestinationOptions configures a HipChat room notification
+type HipChatDestinationOptions struct {
+	URL string `json:"url"`
+}
+
+// DestinationType returns DestinationTypeHipChat
+func (o *HipChatDestinationOptions) DestinationType() string { return DestinationTypeHipChat }
+
+// destinationSecretOptions mirrors the secret list of the configuration schema
+// of the built-in destination types, for Destination.String which doesn't
+// have the schema at hand
+var destinationSecretOptions = map[string][]string{
+	DestinationTypeSlack:          {"url"},
+	DestinationTypeWebhook:        {"url", "password"},
+	DestinationTypePagerDuty:      {"integration_key"},
+	DestinationTypeMattermost:     {"url"},
+	DestinationTypeMicrosoftTeams: {"url"},
+	DestinationTypeGoogleChat:     {"url"},
+	DestinationTypeDiscord:        {"url"},
+	DestinationTypeChatwork:       {"api_token"},
+	DestinationTypeHipChat:        {"url"},
+}
+
+// DestinationOptionsMap converts typed destination options to an options map
+func DestinationOptionsMap(options DestinationOptions) (map[string]interface{}, error) {
+	return structToOptions(options)
+}
+
+// NewDestinationPayload builds the payload to create or update a destination
+// from typed options
+func NewDestinationPayload(name string, options DestinationOptions) (*CreateOrUpdateDestinationPayload, error) {
+	optionsMap, err := DestinationOptionsMap(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateOrUpdateDestinationPayload{
+		Name:    name,
+		Type:    options.DestinationType(),
+		Options: optionsMap,
+	}, nil
+}
+
+// DecodeOptions fills typed options from the destination's options map. The
+// options must be for the destination's type.
+func (d *Destination) DecodeOptions(options DestinationOptions) error {
+	if options.DestinationType() != d.Type {
+		return fmt.Errorf("destination %d is of type %s, not %s", d.Id, d.Type, options.DestinationType())
+	}
+	return optionsToStruct(d.Options, options)
+}
+
+// ValidateDestinationOptions checks typed options against the configuration
+// schema Redash publishes for their type, reporting all problems at once as
+// an *OptionsError
+func (c *Client) ValidateDestinationOptions(options DestinationOptions) error {
+	return c.ValidateDestinationOptionsContext(context.Background(), options)
+}
+
+// ValidateDestinationOptionsContext is like ValidateDestinationOptions but uses ctx for the underlying requests.
+func (c *Client) ValidateDestinationOptionsContext(ctx context.Context, options DestinationOptions) error {
+	optionsMap, err := DestinationOptionsMap(options)
+	if err != nil {
+		return err
+	}
+
+	destinationTypes, err := c.GetDestinationTypesContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, dst := range destinationTypes {
+		if dst.Type == options.DestinationType() {
+			return dst.ConfigurationSchema.validate(dst.Type, optionsMap)
+		}
+	}
+
+	return fmt.Errorf("destination type %s is not available on this Redash instance", options.DestinationType())
+}
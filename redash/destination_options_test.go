@@ -0,0 +1,114 @@
+package redash
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestinationOptionsRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		options DestinationOptions
+		decoded DestinationOptions
+		fields  map[string]interface{}
+	}{
+		{
+			&EmailDestinationOptions{Addresses: EmailAddresses{"oncall@acme.com", "ops@acme.com"}},
+			&EmailDestinationOptions{},
+			map[string]interface{}{"addresses": "oncall@acme.com,ops@acme.com"},
+		},
+		{
+			&SlackDestinationOptions{URL: "https://hooks.slack.com/services/T0/B0/XYZ", Channel: "#alerts"},
+			&SlackDestinationOptions{},
+			map[string]interface{}{"url": "https://hooks.slack.com/services/T0/B0/XYZ", "channel": "#alerts"},
+		},
+		{
+			&WebhookDestinationOptions{URL: "https://hooks.acme.com", Username: "redash", Password: "hunter2"},
+			&WebhookDestinationOptions{},
+			map[string]interface{}{"url": "https://hooks.acme.com", "username": "redash", "password": "hunter2"},
+		},
+		{
+			&PagerDutyDestinationOptions{IntegrationKey: "abc123"},
+			&PagerDutyDestinationOptions{},
+			map[string]interface{}{"integration_key": "abc123"},
+		},
+		{
+			&MattermostDestinationOptions{URL: "https://mm.acme.com/hooks/xyz", Username: "redash"},
+			&MattermostDestinationOptions{},
+			map[string]interface{}{"url": "https://mm.acme.com/hooks/xyz", "username": "redash"},
+		},
+		{
+			&MicrosoftTeamsDestinationOptions{URL: "https://acme.webhook.office.com/xyz"},
+			&MicrosoftTeamsDestinationOptions{},
+			map[string]interface{}{"url": "https://acme.webhook.office.com/xyz"},
+		},
+		{
+			&GoogleChatDestinationOptions{URL: "https://chat.googleapis.com/v1/spaces/xyz", IconURL: "https://acme.com/icon.png"},
+			&GoogleChatDestinationOptions{},
+			map[string]interface{}{"url": "https://chat.googleapis.com/v1/spaces/xyz", "icon_url": "https://acme.com/icon.png"},
+		},
+		{
+			&DiscordDestinationOptions{URL: "https://discord.com/api/webhooks/xyz"},
+			&DiscordDestinationOptions{},
+			map[string]interface{}{"url": "https://discord.com/api/webhooks/xyz"},
+		},
+		{
+			&ChatworkDestinationOptions{APIToken: "token", RoomID: "42", MessageTemplate: "{alert_name} changed state to {new_state}."},
+			&ChatworkDestinationOptions{},
+			map[string]interface{}{"api_token": "token", "room_id": "42", "message_template": "{alert_name} changed state to {new_state}."},
+		},
+		{
+			&HipChatDestinationOptions{URL: "https://acme.hipchat.com/v2/room/1/notification"},
+			&HipChatDestinationOptions{},
+			map[string]interface{}{"url": "https://acme.hipchat.com/v2/room/1/notification"},
+		},
+	}
+
+	for _, test := range tests {
+		payload, err := NewDestinationPayload("Alerts", test.options)
+		assert.Nil(err)
+		assert.Equal(test.options.DestinationType(), payload.Type)
+		assert.Equal(test.fields, payload.Options)
+
+		destination := &Destination{Id: 1, Type: payload.Type, Options: payload.Options}
+		assert.Nil(destination.DecodeOptions(test.decoded))
+		assert.Equal(test.options, test.decoded)
+	}
+
+	destination := &Destination{Id: 1, Type: DestinationTypeSlack}
+	assert.EqualError(destination.DecodeOptions(&EmailDestinationOptions{}), "destination 1 is of type slack, not email")
+}
+
+func TestValidateDestinationOptions(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/destinations/types",
+		httpmock.NewStringResponder(200, `[
+			{"type": "slack", "name": "Slack", "configuration_schema": {
+				"type": "object",
+				"properties": {"url": {"type": "string", "title": "Slack Webhook URL"}},
+				"secret": ["url"]
+			}},
+			{"type": "email", "name": "Email", "configuration_schema": {
+				"type": "object",
+				"properties": {"addresses": {"type": "string"}, "subject_template": {"type": "string", "default": "({state}) {alert_name}"}},
+				"required": ["addresses"]
+			}}
+		]`))
+
+	assert.Nil(c.ValidateDestinationOptions(&SlackDestinationOptions{URL: "https://hooks.slack.com/services/T0/B0/XYZ"}))
+	assert.Nil(c.ValidateDestinationOptions(&EmailDestinationOptions{Addresses: EmailAddresses{"oncall@acme.com"}}))
+
+	err := c.ValidateDestinationOptions(&SlackDestinationOptions{URL: "https://hooks.slack.com/services/T0/B0/XYZ", Channel: "#alerts"})
+	assert.EqualError(err, "invalid options for type slack: channel: unknown option")
+
+	err = c.ValidateDestinationOptions(&DiscordDestinationOptions{URL: "https://discord.com/api/webhooks/xyz"})
+	assert.EqualError(err, "destination type discord is not available on this Redash instance")
+}
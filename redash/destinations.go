@@ -1,13 +1,12 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
 	"strconv"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type Destination struct {
@@ -18,6 +17,15 @@ type Destination struct {
 	Icon    string                 `json:"icon,omitempty"`
 }
 
+// String formats the Destination with the secrets of its type, and any option
+// named like a credential, redacted
+func (d Destination) String() string {
+	type destination Destination
+	redacted := destination(d)
+	redacted.Options = redactTypedOptions(d.Options, destinationSecretOptions[d.Type])
+	return fmt.Sprintf("%+v", redacted)
+}
+
 type DestinationType struct {
 	Name                string                             `json:"name,omitempty"`
 	Type                string                             `json:"type,omitempty"`
@@ -25,19 +33,9 @@ type DestinationType struct {
 	ConfigurationSchema DestinationTypeConfigurationSchema `json:"configuration_schema,omitempty"`
 }
 
-type DestinationTypeConfigurationSchema struct {
-	Secret     interface{}                             `json:"secret,omitempty"`
-	Required   []string                                `json:"required,omitempty"`
-	Type       string                                  `json:"type,omitempty"`
-	Order      []string                                `json:"order,omitempty"`
-	Properties map[string]DestinationTypePropertyField `json:"properties,omitempty"`
-}
+type DestinationTypeConfigurationSchema = ConfigurationSchema
 
-type DestinationTypePropertyField struct {
-	Type    string
-	Title   string
-	Default interface{}
-}
+type DestinationTypePropertyField = ConfigurationProperty
 
 type CreateOrUpdateDestinationPayload struct {
 	Name    string                 `json:"name,omitempty"`
@@ -45,9 +43,22 @@ type CreateOrUpdateDestinationPayload struct {
 	Type    string                 `json:"type,omitempty"`
 }
 
+// String formats the payload with its secret options redacted, see Destination.String
+func (p CreateOrUpdateDestinationPayload) String() string {
+	type payload CreateOrUpdateDestinationPayload
+	redacted := payload(p)
+	redacted.Options = redactTypedOptions(p.Options, destinationSecretOptions[p.Type])
+	return fmt.Sprintf("%+v", redacted)
+}
+
 func (c *Client) GetDestinations() (*[]Destination, error) {
+	return c.GetDestinationsContext(context.Background())
+}
+
+// GetDestinationsContext is like GetDestinations but uses ctx for the underlying requests.
+func (c *Client) GetDestinationsContext(ctx context.Context) (*[]Destination, error) {
 	path := "/api/destinations"
-	res, err := c.get(path, url.Values{})
+	res, err := c.get(ctx, path, url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -65,8 +76,13 @@ func (c *Client) GetDestinations() (*[]Destination, error) {
 }
 
 func (c *Client) GetDestination(id int) (*Destination, error) {
+	return c.GetDestinationContext(context.Background(), id)
+}
+
+// GetDestinationContext is like GetDestination but uses ctx for the underlying requests.
+func (c *Client) GetDestinationContext(ctx context.Context, id int) (*Destination, error) {
 	path := "/api/destinations/" + strconv.Itoa(id)
-	res, err := c.get(path, url.Values{})
+	res, err := c.get(ctx, path, url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -86,53 +102,26 @@ func (c *Client) GetDestination(id int) (*Destination, error) {
 	return &destination, nil
 }
 
+// SanitizeDestinationOptions checks the options of a destination against the
+// configuration schema of its type, see SanitizeDataSourceOptions
 func (c *Client) SanitizeDestinationOptions(destination *CreateOrUpdateDestinationPayload) (*CreateOrUpdateDestinationPayload, error) {
-	destinationTypes, err := c.GetDestinationTypes()
+	return c.SanitizeDestinationOptionsContext(context.Background(), destination)
+}
+
+// SanitizeDestinationOptionsContext is like SanitizeDestinationOptions but uses ctx for the underlying requests.
+func (c *Client) SanitizeDestinationOptionsContext(ctx context.Context, destination *CreateOrUpdateDestinationPayload) (*CreateOrUpdateDestinationPayload, error) {
+	destinationTypes, err := c.GetDestinationTypesContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, dst := range destinationTypes {
 		if dst.Type == destination.Type {
-			for _, required := range dst.ConfigurationSchema.Required {
-				_, exists := destination.Options[required]
-				if !exists {
-					return nil, fmt.Errorf("Required Field missiong: %s", required)
-				}
-			}
-
-			for propName, propVal := range destination.Options {
-				_, exists := dst.ConfigurationSchema.Properties[propName]
-				if !exists {
-					if c.IsStrict() {
-						return nil, fmt.Errorf("Invalid field (%s) for type: %s", propName, destination.Type)
-					}
-
-					log.Warn(fmt.Sprintf("[WARN] Ignoring invalid field (%s) for type: %s", propName, destination.Type))
-					delete((*destination).Options, propName)
-					continue
-				}
-
-				switch propVal.(type) {
-				case int:
-					if dst.ConfigurationSchema.Properties[propName].Type != "number" {
-						return nil, fmt.Errorf("Invalid value type for %s", propName)
-					}
-				case string:
-					if dst.ConfigurationSchema.Properties[propName].Type != "string" {
-
-						return nil, fmt.Errorf("Invalid value type for %s", propName)
-					}
-
-				case bool:
-
-					if dst.ConfigurationSchema.Properties[propName].Type != "boolean" {
-						return nil, fmt.Errorf("Invalid value type for %s", propName)
-					}
-				default:
-					return nil, fmt.Errorf("Invalid value type for %s", propName)
-				}
+			options, err := dst.ConfigurationSchema.sanitize(destination.Type, destination.Options, c.IsStrict())
+			if err != nil {
+				return nil, err
 			}
+			destination.Options = options
 		}
 	}
 
@@ -140,11 +129,22 @@ func (c *Client) SanitizeDestinationOptions(destination *CreateOrUpdateDestinati
 }
 
 func (c *Client) CreateDestination(destinationPayload *CreateOrUpdateDestinationPayload) (*Destination, error) {
+	return c.CreateDestinationContext(context.Background(), destinationPayload)
+}
+
+// CreateDestinationContext is like CreateDestination but uses ctx for the underlying requests.
+func (c *Client) CreateDestinationContext(ctx context.Context, destinationPayload *CreateOrUpdateDestinationPayload) (*Destination, error) {
 	path := "/api/destinations"
 
-	destinationPayload, err := c.SanitizeDestinationOptions(destinationPayload)
+	destinationPayload, err := c.prepareDestination(ctx, destinationPayload)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMaskedSecrets(destinationPayload.Options); err != nil {
+		return nil, err
+	}
 
-	fmt.Printf("%+v\n", destinationPayload)
+	destinationPayload, err = c.SanitizeDestinationOptionsContext(ctx, destinationPayload)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +154,7 @@ func (c *Client) CreateDestination(destinationPayload *CreateOrUpdateDestination
 		return nil, err
 	}
 
-	res, err := c.post(path, string(payload), url.Values{})
+	res, err := c.post(ctx, path, string(payload), url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -175,9 +175,19 @@ func (c *Client) CreateDestination(destinationPayload *CreateOrUpdateDestination
 }
 
 func (c *Client) UpdateDestination(id int, destinationPayload *CreateOrUpdateDestinationPayload) (*Destination, error) {
+	return c.UpdateDestinationContext(context.Background(), id, destinationPayload)
+}
+
+// UpdateDestinationContext is like UpdateDestination but uses ctx for the underlying requests.
+func (c *Client) UpdateDestinationContext(ctx context.Context, id int, destinationPayload *CreateOrUpdateDestinationPayload) (*Destination, error) {
 	path := "/api/destinations/" + strconv.Itoa(id)
 
-	destinationPayload, err := c.SanitizeDestinationOptions(destinationPayload)
+	destinationPayload, err := c.prepareDestination(ctx, destinationPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationPayload, err = c.SanitizeDestinationOptionsContext(ctx, destinationPayload)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +197,7 @@ func (c *Client) UpdateDestination(id int, destinationPayload *CreateOrUpdateDes
 		return nil, err
 	}
 
-	res, err := c.post(path, string(payload), url.Values{})
+	res, err := c.post(ctx, path, string(payload), url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -207,19 +217,79 @@ func (c *Client) UpdateDestination(id int, destinationPayload *CreateOrUpdateDes
 	return &destination, nil
 }
 
+// prepareDestination returns a copy of the payload with its SecretRefs resolved
+func (c *Client) prepareDestination(ctx context.Context, destinationPayload *CreateOrUpdateDestinationPayload) (*CreateOrUpdateDestinationPayload, error) {
+	options, err := c.resolveSecrets(ctx, destinationPayload.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared := *destinationPayload
+	prepared.Options = options
+	return &prepared, nil
+}
+
+// PatchDestination applies changes to an existing destination, keeping masked
+// secrets as stored and skipping the update when nothing would change. See
+// PatchDataSource.
+func (c *Client) PatchDestination(id int, changes *CreateOrUpdateDestinationPayload) (*Destination, error) {
+	return c.PatchDestinationContext(context.Background(), id, changes)
+}
+
+// PatchDestinationContext is like PatchDestination but uses ctx for the underlying requests.
+func (c *Client) PatchDestinationContext(ctx context.Context, id int, changes *CreateOrUpdateDestinationPayload) (*Destination, error) {
+	if changes == nil {
+		changes = &CreateOrUpdateDestinationPayload{}
+	}
+
+	current, err := c.GetDestinationContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := &CreateOrUpdateDestinationPayload{
+		Name:    current.Name,
+		Type:    current.Type,
+		Options: mergeOptions(current.Options, changes.Options),
+	}
+	if changes.Name != "" {
+		updated.Name = changes.Name
+	}
+	if changes.Type != "" {
+		updated.Type = changes.Type
+	}
+
+	if updated.Name == current.Name && updated.Type == current.Type && optionsEqual(updated.Options, current.Options) {
+		return current, nil
+	}
+
+	return c.UpdateDestinationContext(ctx, id, updated)
+}
+
 func (c *Client) DeleteDestination(id int) error {
+	return c.DeleteDestinationContext(context.Background(), id)
+}
+
+// DeleteDestinationContext is like DeleteDestination but uses ctx for the underlying requests.
+func (c *Client) DeleteDestinationContext(ctx context.Context, id int) error {
 	path := "/api/destination/" + strconv.Itoa(id)
 
-	_, err := c.delete(path, url.Values{})
+	response, err := c.delete(ctx, path, url.Values{})
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 	return nil
 }
 
 func (c *Client) GetDestinationTypes() ([]DestinationType, error) {
+	return c.GetDestinationTypesContext(context.Background())
+}
+
+// GetDestinationTypesContext is like GetDestinationTypes but uses ctx for the underlying requests.
+func (c *Client) GetDestinationTypesContext(ctx context.Context) ([]DestinationType, error) {
 	path := "/api/destinations/types"
-	res, err := c.get(path, url.Values{})
+	res, err := c.get(ctx, path, url.Values{})
 	if err != nil {
 		return nil, err
 	}
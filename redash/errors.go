@@ -0,0 +1,114 @@
+package redash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors matched by *APIError through errors.Is
+var (
+	ErrBadRequest   = errors.New("redash: bad request")
+	ErrUnauthorized = errors.New("redash: unauthorized")
+	ErrForbidden    = errors.New("redash: forbidden")
+	ErrNotFound     = errors.New("redash: not found")
+	ErrConflict     = errors.New("redash: conflict")
+)
+
+// APIError is returned for any non-2xx response from the Redash API
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Body       string
+	// Message is the "message" field of the Redash error payload, if any
+	Message string
+}
+
+// Error keeps the format used by earlier versions of the client
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d from %s request to %s: %s", e.StatusCode, e.Method, e.URL, e.Body)
+}
+
+// Is reports whether the status code of e corresponds to the target sentinel
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// newAPIError builds an *APIError from a response body, extracting the Redash
+// error message when the body is JSON
+func newAPIError(statusCode int, method, url, body string) *APIError {
+	apiError := &APIError{
+		StatusCode: statusCode,
+		Method:     method,
+		URL:        url,
+		Body:       body,
+	}
+
+	payload := struct {
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal([]byte(body), &payload); err == nil {
+		apiError.Message = payload.Message
+	}
+
+	return apiError
+}
+
+// notFoundError is returned when a resource is looked up client-side, e.g. a
+// widget inside a dashboard, and does not exist
+type notFoundError struct {
+	message string
+}
+
+func newNotFoundError(format string, args ...interface{}) error {
+	return &notFoundError{message: fmt.Sprintf(format, args...)}
+}
+
+func (e *notFoundError) Error() string {
+	return e.message
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// IsBadRequest returns true if err is a 400 from the Redash API
+func IsBadRequest(err error) bool {
+	return errors.Is(err, ErrBadRequest)
+}
+
+// IsUnauthorized returns true if err is a 401 from the Redash API
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden returns true if err is a 403 from the Redash API
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsNotFound returns true if err is a 404 from the Redash API or a resource
+// that could not be found client-side
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict returns true if err is a 409 from the Redash API, typically a
+// stale version on update
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
@@ -0,0 +1,103 @@
+package redash
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// EventList models the response from Redash's /api/events endpoint
+type EventList struct {
+	Count    int     `json:"count"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+	Results  []Event `json:"results"`
+}
+
+// Event models an audit log entry. Listing events requires an admin API key.
+type Event struct {
+	OrgID      int                    `json:"org_id"`
+	UserID     int                    `json:"user_id"`
+	UserName   string                 `json:"user_name"`
+	Action     string                 `json:"action"`
+	ObjectType string                 `json:"object_type"`
+	ObjectID   interface{}            `json:"object_id"`
+	Browser    string                 `json:"browser"`
+	Location   string                 `json:"location"`
+	Details    map[string]interface{} `json:"details"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// EventListOptions selects the page of events returned by GetEvents
+type EventListOptions struct {
+	Page     int
+	PageSize int
+}
+
+func (o *EventListOptions) values() url.Values {
+	queryParams := url.Values{}
+	if o == nil {
+		return queryParams
+	}
+
+	setPageValues(queryParams, o.Page, o.PageSize)
+
+	return queryParams
+}
+
+// GetEvents returns a page of the audit log, newest first
+func (c *Client) GetEvents(options *EventListOptions) (*EventList, error) {
+	return c.GetEventsContext(context.Background(), options)
+}
+
+// GetEventsContext is like GetEvents but uses ctx for the underlying requests.
+func (c *Client) GetEventsContext(ctx context.Context, options *EventListOptions) (*EventList, error) {
+	path := "/api/events"
+
+	response, err := c.get(ctx, path, options.values())
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	events := new(EventList)
+	err = json.NewDecoder(response.Body).Decode(events)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// EventsPager iterates over the whole audit log
+func (c *Client) EventsPager(options *EventListOptions) *Pager[Event] {
+	return c.EventsPagerContext(context.Background(), options)
+}
+
+// EventsPagerContext is like EventsPager but uses ctx for the underlying requests.
+func (c *Client) EventsPagerContext(ctx context.Context, options *EventListOptions) *Pager[Event] {
+	pageOptions := EventListOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+
+	return NewPager(ctx, pageOptions.Page, pageOptions.PageSize, func(ctx context.Context, page int) ([]Event, int, error) {
+		pageOptions.Page = page
+		events, err := c.GetEventsContext(ctx, &pageOptions)
+		if err != nil {
+			return nil, 0, err
+		}
+		return events.Results, events.Count, nil
+	})
+}
+
+// ListAllEvents returns the audit log, up to limit events when limit is positive
+func (c *Client) ListAllEvents(options *EventListOptions, limit int) ([]Event, error) {
+	return c.ListAllEventsContext(context.Background(), options, limit)
+}
+
+// ListAllEventsContext is like ListAllEvents but uses ctx for the underlying requests.
+func (c *Client) ListAllEventsContext(ctx context.Context, options *EventListOptions, limit int) ([]Event, error) {
+	return ListAll(c.EventsPagerContext(ctx, options), limit)
+}
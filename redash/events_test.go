@@ -0,0 +1,34 @@
+package redash
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAllEvents(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/events", "page=1&page_size=2",
+		httpmock.NewStringResponder(200, `{"count": 3, "page": 1, "page_size": 2, "results": [
+			{"org_id": 1, "user_id": 1, "user_name": "admin", "action": "view", "object_type": "query", "object_id": "5", "created_at": "2023-01-03T02:57:24.435Z"},
+			{"org_id": 1, "user_id": 2, "user_name": "developer", "action": "edit", "object_type": "dashboard", "object_id": "1", "created_at": "2023-01-03T02:50:00.000Z"}
+		]}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/events", "page=2&page_size=2",
+		httpmock.NewStringResponder(200, `{"count": 3, "page": 2, "page_size": 2, "results": [
+			{"org_id": 1, "user_id": 1, "user_name": "admin", "action": "login", "object_type": "redash", "object_id": null, "details": {"ip": "127.0.0.1"}, "created_at": "2023-01-03T02:40:00.000Z"}
+		]}`))
+
+	events, err := c.ListAllEvents(&EventListOptions{PageSize: 2}, 0)
+	assert.Nil(err)
+	assert.Equal(3, len(events))
+	assert.Equal("view", events[0].Action)
+	assert.Equal("5", events[0].ObjectID)
+	assert.Equal("login", events[2].Action)
+	assert.Equal("127.0.0.1", events[2].Details["ip"])
+}
@@ -14,6 +14,7 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/url"
@@ -47,10 +48,15 @@ type GroupCreatePayload struct {
 
 // GetGroups returns a list of Redash groups
 func (c *Client) GetGroups() (*[]Group, error) {
+	return c.GetGroupsContext(context.Background())
+}
+
+// GetGroupsContext is like GetGroups but uses ctx for the underlying requests.
+func (c *Client) GetGroupsContext(ctx context.Context) (*[]Group, error) {
 	path := "/api/groups"
 
 	query := url.Values{}
-	response, err := c.get(path, query)
+	response, err := c.get(ctx, path, query)
 
 	if err != nil {
 		return nil, err
@@ -69,10 +75,15 @@ func (c *Client) GetGroups() (*[]Group, error) {
 
 // GetGroup returns an individual Redash group
 func (c *Client) GetGroup(id int) (*Group, error) {
+	return c.GetGroupContext(context.Background(), id)
+}
+
+// GetGroupContext is like GetGroup but uses ctx for the underlying requests.
+func (c *Client) GetGroupContext(ctx context.Context, id int) (*Group, error) {
 	path := "/api/groups/" + strconv.Itoa(id)
 
 	query := url.Values{}
-	response, err := c.get(path, query)
+	response, err := c.get(ctx, path, query)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +106,11 @@ func (c *Client) GetGroup(id int) (*Group, error) {
 
 // CreateGroup creates a new Redash group
 func (c *Client) CreateGroup(groupPayload *GroupCreatePayload) (*Group, error) {
+	return c.CreateGroupContext(context.Background(), groupPayload)
+}
+
+// CreateGroupContext is like CreateGroup but uses ctx for the underlying requests.
+func (c *Client) CreateGroupContext(ctx context.Context, groupPayload *GroupCreatePayload) (*Group, error) {
 	path := "/api/groups"
 
 	payload, err := json.Marshal(groupPayload)
@@ -103,7 +119,7 @@ func (c *Client) CreateGroup(groupPayload *GroupCreatePayload) (*Group, error) {
 	}
 
 	query := url.Values{}
-	response, err := c.post(path, string(payload), query)
+	response, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return nil, err
 	}
@@ -126,6 +142,11 @@ func (c *Client) CreateGroup(groupPayload *GroupCreatePayload) (*Group, error) {
 
 // UpdateGroup updates an existing Redash group
 func (c *Client) UpdateGroup(id int, group *Group) (*Group, error) {
+	return c.UpdateGroupContext(context.Background(), id, group)
+}
+
+// UpdateGroupContext is like UpdateGroup but uses ctx for the underlying requests.
+func (c *Client) UpdateGroupContext(ctx context.Context, id int, group *Group) (*Group, error) {
 	path := "/api/groups/" + strconv.Itoa(id)
 
 	payload, err := json.Marshal(group)
@@ -134,7 +155,7 @@ func (c *Client) UpdateGroup(id int, group *Group) (*Group, error) {
 	}
 
 	query := url.Values{}
-	response, err := c.post(path, string(payload), query)
+	response, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return nil, err
 	}
@@ -155,19 +176,30 @@ func (c *Client) UpdateGroup(id int, group *Group) (*Group, error) {
 
 // DeleteGroup deletes a Redash group
 func (c *Client) DeleteGroup(id int) error {
+	return c.DeleteGroupContext(context.Background(), id)
+}
+
+// DeleteGroupContext is like DeleteGroup but uses ctx for the underlying requests.
+func (c *Client) DeleteGroupContext(ctx context.Context, id int) error {
 	path := "/api/groups/" + strconv.Itoa(id)
 
 	query := url.Values{}
-	_, err := c.delete(path, query)
+	response, err := c.delete(ctx, path, query)
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 
 	return nil
 }
 
 // GroupAddUser adds a user to a Redash group
 func (c *Client) GroupAddUser(groupID int, userID int) error {
+	return c.GroupAddUserContext(context.Background(), groupID, userID)
+}
+
+// GroupAddUserContext is like GroupAddUser but uses ctx for the underlying requests.
+func (c *Client) GroupAddUserContext(ctx context.Context, groupID int, userID int) error {
 	path := "/api/groups/" + strconv.Itoa(groupID) + "/members"
 
 	user := GroupUser{userID}
@@ -177,7 +209,7 @@ func (c *Client) GroupAddUser(groupID int, userID int) error {
 	}
 
 	query := url.Values{}
-	response, err := c.post(path, string(payload), query)
+	response, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return err
 	}
@@ -188,10 +220,15 @@ func (c *Client) GroupAddUser(groupID int, userID int) error {
 
 // GroupRemoveUser removes a user from a Redash group
 func (c *Client) GroupRemoveUser(groupID int, userID int) error {
+	return c.GroupRemoveUserContext(context.Background(), groupID, userID)
+}
+
+// GroupRemoveUserContext is like GroupRemoveUser but uses ctx for the underlying requests.
+func (c *Client) GroupRemoveUserContext(ctx context.Context, groupID int, userID int) error {
 	path := "/api/groups/" + strconv.Itoa(groupID) + "/members/" + strconv.Itoa(userID)
 
 	query := url.Values{}
-	response, err := c.delete(path, query)
+	response, err := c.delete(ctx, path, query)
 	if err != nil {
 		return err
 	}
@@ -202,6 +239,11 @@ func (c *Client) GroupRemoveUser(groupID int, userID int) error {
 
 // GroupAddDataSource adds a Data Source to a Redash group
 func (c *Client) GroupAddDataSource(groupID int, dataSourceID int) error {
+	return c.GroupAddDataSourceContext(context.Background(), groupID, dataSourceID)
+}
+
+// GroupAddDataSourceContext is like GroupAddDataSource but uses ctx for the underlying requests.
+func (c *Client) GroupAddDataSourceContext(ctx context.Context, groupID int, dataSourceID int) error {
 	path := "/api/groups/" + strconv.Itoa(groupID) + "/data_sources"
 
 	dataSource := GroupDataSource{dataSourceID}
@@ -211,7 +253,7 @@ func (c *Client) GroupAddDataSource(groupID int, dataSourceID int) error {
 	}
 
 	query := url.Values{}
-	response, err := c.post(path, string(payload), query)
+	response, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return err
 	}
@@ -222,10 +264,15 @@ func (c *Client) GroupAddDataSource(groupID int, dataSourceID int) error {
 
 // GroupRemoveDataSource removes a Data Source from a Redash group
 func (c *Client) GroupRemoveDataSource(groupID int, dataSourceID int) error {
+	return c.GroupRemoveDataSourceContext(context.Background(), groupID, dataSourceID)
+}
+
+// GroupRemoveDataSourceContext is like GroupRemoveDataSource but uses ctx for the underlying requests.
+func (c *Client) GroupRemoveDataSourceContext(ctx context.Context, groupID int, dataSourceID int) error {
 	path := "/api/groups/" + strconv.Itoa(groupID) + "/data_sources/" + strconv.Itoa(dataSourceID)
 
 	query := url.Values{}
-	response, err := c.delete(path, query)
+	response, err := c.delete(ctx, path, query)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,193 @@
+package redash
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a client-side token bucket. Requests are spaced so that
+// on average no more than RequestsPerSecond are sent, with bursts of up to
+// Burst requests.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// LimiterStats is a snapshot of the client-side limiter state
+type LimiterStats struct {
+	// InFlight is the number of requests currently holding a concurrency slot
+	InFlight int
+	// Waiting is the number of requests queued for a slot or a token
+	Waiting int
+	// TotalRequests counts requests admitted since the client was created
+	TotalRequests int64
+	// TotalWaits counts admitted requests that had to wait
+	TotalWaits int64
+	// TotalWaitTime is the cumulative time requests spent waiting
+	TotalWaitTime time.Duration
+}
+
+// limiter combines an optional token bucket with an optional max-in-flight
+// semaphore. It is safe for concurrent use.
+type limiter struct {
+	mu sync.Mutex
+
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	slots chan struct{}
+
+	stats LimiterStats
+}
+
+// newLimiter returns nil when the config requests neither rate limiting nor a
+// concurrency cap
+func newLimiter(config *Config) *limiter {
+	rateLimited := config.RateLimit != nil && config.RateLimit.RequestsPerSecond > 0
+	if !rateLimited && config.MaxInFlight <= 0 {
+		return nil
+	}
+
+	l := &limiter{}
+	if rateLimited {
+		l.rate = config.RateLimit.RequestsPerSecond
+		l.burst = float64(config.RateLimit.Burst)
+		if l.burst < 1 {
+			l.burst = 1
+		}
+		l.tokens = l.burst
+		l.last = time.Now()
+	}
+	if config.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, config.MaxInFlight)
+	}
+
+	return l
+}
+
+// acquire blocks until the request may be sent. The returned function must be
+// called once the request has completed to free its concurrency slot.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	start := time.Now()
+	l.mu.Lock()
+	l.stats.Waiting++
+	l.mu.Unlock()
+
+	err := l.wait(ctx)
+
+	waited := time.Since(start)
+	l.mu.Lock()
+	l.stats.Waiting--
+	if err == nil {
+		l.stats.TotalRequests++
+		l.stats.TotalWaitTime += waited
+		if waited > time.Millisecond {
+			l.stats.TotalWaits++
+		}
+		if l.slots != nil {
+			l.stats.InFlight++
+		}
+	}
+	l.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if l.slots == nil {
+				return
+			}
+			<-l.slots
+			l.mu.Lock()
+			l.stats.InFlight--
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if l.rate > 0 {
+		if err := sleepContext(ctx, l.reserve()); err != nil {
+			l.mu.Lock()
+			l.tokens++
+			l.mu.Unlock()
+			if l.slots != nil {
+				<-l.slots
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reserve takes a token from the bucket, possibly going into debt, and returns
+// how long the caller has to wait for that token to become valid
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+func (l *limiter) snapshot() LimiterStats {
+	if l == nil {
+		return LimiterStats{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// releaseOnClose frees a limiter slot once the response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
+
+// LimiterStats returns the current state of the client-side rate limiter and
+// concurrency cap. It returns zero values when neither is configured.
+func (c *Client) LimiterStats() LimiterStats {
+	return c.limiter.snapshot()
+}
@@ -0,0 +1,129 @@
+package redash
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// PageFetcher loads a single 1-indexed page and returns its items along with
+// the total number of items across all pages
+type PageFetcher[T any] func(ctx context.Context, page int) ([]T, int, error)
+
+// Pager iterates lazily over every item of a paginated list endpoint, fetching
+// the next page only once the current one is consumed:
+//
+//	pager := c.QueriesPager(nil)
+//	for pager.Next() {
+//		query := pager.Item()
+//		...
+//	}
+//	if err := pager.Err(); err != nil {
+//		...
+//	}
+type Pager[T any] struct {
+	ctx   context.Context
+	fetch PageFetcher[T]
+
+	page  int
+	items []T
+	index int
+	count int
+	seen  int
+	done  bool
+	err   error
+
+	// filter drops items client-side without affecting the page accounting
+	filter func(T) bool
+}
+
+// defaultPageSize is the page size Redash uses when page_size is not set
+const defaultPageSize = 25
+
+// NewPager returns a Pager starting at firstPage, or page 1 if firstPage is
+// not positive. pageSize is the number of items per page, or the Redash
+// default if not positive, and accounts for the items on the skipped pages.
+func NewPager[T any](ctx context.Context, firstPage, pageSize int, fetch PageFetcher[T]) *Pager[T] {
+	if firstPage < 1 {
+		firstPage = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	return &Pager[T]{ctx: ctx, fetch: fetch, page: firstPage, index: -1, seen: (firstPage - 1) * pageSize}
+}
+
+// Next advances to the next item, fetching a new page when needed. It returns
+// false when all items were consumed or an error occurred.
+func (p *Pager[T]) Next() bool {
+	for p.err == nil {
+		if p.index+1 < len(p.items) {
+			p.index++
+			p.seen++
+			if p.filter == nil || p.filter(p.items[p.index]) {
+				return true
+			}
+			continue
+		}
+
+		if p.done {
+			return false
+		}
+
+		items, count, err := p.fetch(p.ctx, p.page)
+		if err != nil {
+			p.err = err
+			return false
+		}
+
+		p.page++
+		p.count = count
+		p.items = items
+		p.index = -1
+		if len(items) == 0 || p.seen+len(items) >= count {
+			p.done = true
+		}
+	}
+
+	return false
+}
+
+// Item returns the current item. It is only valid after Next returned true.
+func (p *Pager[T]) Item() T {
+	return p.items[p.index]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// Count returns the total number of items reported by Redash. It is zero until
+// the first page has been fetched.
+func (p *Pager[T]) Count() int {
+	return p.count
+}
+
+// ListAll collects the remaining items of a pager. When limit is positive at
+// most limit items are returned and no further pages are fetched.
+func ListAll[T any](pager *Pager[T], limit int) ([]T, error) {
+	items := []T{}
+	for (limit <= 0 || len(items) < limit) && pager.Next() {
+		items = append(items, pager.Item())
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// setPageValues adds the page and page_size query parameters when set
+func setPageValues(queryParams url.Values, page, pageSize int) {
+	if page > 0 {
+		queryParams.Set("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		queryParams.Set("page_size", strconv.Itoa(pageSize))
+	}
+}
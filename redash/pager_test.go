@@ -0,0 +1,96 @@
+package redash
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPager(t *testing.T) {
+	assert := assert.New(t)
+
+	pages := [][]int{{1, 2, 3}, {4, 5, 6}, {7}}
+	var fetched []int
+	pager := NewPager(context.Background(), 0, 3, func(ctx context.Context, page int) ([]int, int, error) {
+		fetched = append(fetched, page)
+		return pages[page-1], 7, nil
+	})
+
+	items, err := ListAll(pager, 0)
+	assert.Nil(err)
+	assert.Equal([]int{1, 2, 3, 4, 5, 6, 7}, items)
+	assert.Equal([]int{1, 2, 3}, fetched)
+	assert.Equal(7, pager.Count())
+	assert.False(pager.Next())
+}
+
+func TestPagerLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	var fetched []int
+	pager := NewPager(context.Background(), 1, 2, func(ctx context.Context, page int) ([]int, int, error) {
+		fetched = append(fetched, page)
+		return []int{page*10 + 1, page*10 + 2}, 100, nil
+	})
+
+	items, err := ListAll(pager, 3)
+	assert.Nil(err)
+	assert.Equal([]int{11, 12, 21}, items)
+	assert.Equal([]int{1, 2}, fetched)
+}
+
+func TestPagerFromLaterPage(t *testing.T) {
+	assert := assert.New(t)
+
+	var fetched []int
+	pager := NewPager(context.Background(), 2, 2, func(ctx context.Context, page int) ([]int, int, error) {
+		fetched = append(fetched, page)
+		if page > 2 {
+			return nil, 0, errors.New("Page is out of range")
+		}
+		return []int{3, 4}, 4, nil
+	})
+
+	items, err := ListAll(pager, 0)
+	assert.Nil(err)
+	assert.Equal([]int{3, 4}, items)
+	assert.Equal([]int{2}, fetched)
+}
+
+func TestPagerStopsOnEmptyPage(t *testing.T) {
+	assert := assert.New(t)
+
+	// Count overstates the number of items, e.g. because some were deleted
+	// while iterating
+	pager := NewPager(context.Background(), 1, 2, func(ctx context.Context, page int) ([]int, int, error) {
+		if page > 1 {
+			return []int{}, 10, nil
+		}
+		return []int{1, 2}, 10, nil
+	})
+
+	items, err := ListAll(pager, 0)
+	assert.Nil(err)
+	assert.Equal([]int{1, 2}, items)
+}
+
+func TestPagerError(t *testing.T) {
+	assert := assert.New(t)
+
+	pager := NewPager(context.Background(), 1, 2, func(ctx context.Context, page int) ([]int, int, error) {
+		if page > 1 {
+			return nil, 0, errors.New("boom")
+		}
+		return []int{1, 2}, 4, nil
+	})
+
+	assert.True(pager.Next())
+	assert.True(pager.Next())
+	assert.False(pager.Next())
+	assert.EqualError(pager.Err(), "boom")
+
+	_, err := ListAll(pager, 0)
+	assert.EqualError(err, "boom")
+}
@@ -1,7 +1,10 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 	"time"
@@ -9,33 +12,170 @@ import (
 
 // QueriesList models the response from Redash's /api/queries endpoint
 type QueriesList struct {
-	Count    int `json:"count"`
-	Page     int `json:"page"`
-	PageSize int `json:"page_size"`
-	Results  []struct {
-		ID                int           `json:"id"`
-		IsArchived        bool          `json:"is_archived"`
-		CreatedAt         time.Time     `json:"created_at"`
-		RetrievedAt       time.Time     `json:"retrieved_at"`
-		UpdatedAt         time.Time     `json:"updated_at"`
-		Name              string        `json:"name"`
-		Description       string        `json:"description"`
-		Query             string        `json:"query"`
-		QueryHash         string        `json:"query_hash"`
-		Version           int           `json:"version"`
-		LastModifiedByID  int           `json:"last_modified_by_id"`
-		Tags              []string      `json:"tags"`
-		APIKey            string        `json:"api_key"`
-		DataSourceID      int           `json:"data_source_id"`
-		LatestQueryDataID int           `json:"latest_query_data_id"`
-		Schedule          QuerySchedule `json:"schedule"`
-		User              User          `json:"user"`
-		IsFavorite        bool          `json:"is_favorite"`
-		IsDraft           bool          `json:"is_draft"`
-		IsSafe            bool          `json:"is_safe"`
-		Runtime           float32       `json:"runtime"`
-		Options           QueryOptions  `json:"options"`
+	Count    int            `json:"count"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Results  []QuerySummary `json:"results"`
+}
+
+// QuerySummary models a single query within a QueriesList
+type QuerySummary struct {
+	ID                int           `json:"id"`
+	IsArchived        bool          `json:"is_archived"`
+	CreatedAt         time.Time     `json:"created_at"`
+	RetrievedAt       time.Time     `json:"retrieved_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	Name              string        `json:"name"`
+	Description       string        `json:"description"`
+	Query             string        `json:"query"`
+	QueryHash         string        `json:"query_hash"`
+	Version           int           `json:"version"`
+	LastModifiedByID  int           `json:"last_modified_by_id"`
+	Tags              []string      `json:"tags"`
+	APIKey            string        `json:"api_key"`
+	DataSourceID      int           `json:"data_source_id"`
+	LatestQueryDataID int           `json:"latest_query_data_id"`
+	Schedule          QuerySchedule `json:"schedule"`
+	User              User          `json:"user"`
+	IsFavorite        bool          `json:"is_favorite"`
+	IsDraft           bool          `json:"is_draft"`
+	IsSafe            bool          `json:"is_safe"`
+	Runtime           float32       `json:"runtime"`
+	Options           QueryOptions  `json:"options"`
+}
+
+// ToQuery converts a list entry into a Query. Fields only returned by
+// GetQuery, such as Visualizations and CanEdit, are left empty.
+func (q QuerySummary) ToQuery() *Query {
+	return &Query{
+		ID:                q.ID,
+		Name:              q.Name,
+		Description:       q.Description,
+		Query:             q.Query,
+		QueryHash:         q.QueryHash,
+		Version:           q.Version,
+		Schedule:          q.Schedule,
+		APIKey:            q.APIKey,
+		IsArchived:        q.IsArchived,
+		IsDraft:           q.IsDraft,
+		UpdatedAt:         q.UpdatedAt,
+		CreatedAt:         q.CreatedAt,
+		DataSourceID:      q.DataSourceID,
+		LatestQueryDataID: q.LatestQueryDataID,
+		Tags:              q.Tags,
+		IsSafe:            q.IsSafe,
+		User:              q.User,
+		LastModifiedByID:  q.LastModifiedByID,
+		IsFavorite:        q.IsFavorite,
+		Options:           q.Options,
+		RetrievedAt:       q.RetrievedAt,
+		Runtime:           q.Runtime,
+	}
+}
+
+// Summary converts a Query into the shape of a QueriesList entry
+func (q *Query) Summary() QuerySummary {
+	lastModifiedByID := q.LastModifiedByID
+	if lastModifiedByID == 0 {
+		lastModifiedByID = q.LastModifiedBy.ID
+	}
+
+	return QuerySummary{
+		ID:                q.ID,
+		IsArchived:        q.IsArchived,
+		CreatedAt:         q.CreatedAt,
+		RetrievedAt:       q.RetrievedAt,
+		UpdatedAt:         q.UpdatedAt,
+		Name:              q.Name,
+		Description:       q.Description,
+		Query:             q.Query,
+		QueryHash:         q.QueryHash,
+		Version:           q.Version,
+		LastModifiedByID:  lastModifiedByID,
+		Tags:              q.Tags,
+		APIKey:            q.APIKey,
+		DataSourceID:      q.DataSourceID,
+		LatestQueryDataID: q.LatestQueryDataID,
+		Schedule:          q.Schedule,
+		User:              q.User,
+		IsFavorite:        q.IsFavorite,
+		IsDraft:           q.IsDraft,
+		IsSafe:            q.IsSafe,
+		Runtime:           q.Runtime,
+		Options:           q.Options,
+	}
+}
+
+// QueryListOptions filters, orders and paginates the queries returned by
+// ListQueries. At most one of Archived, OnlyFavorites and OnlyMine may be set,
+// each maps to its own Redash endpoint.
+type QueryListOptions struct {
+	// Search matches query names, descriptions and SQL text
+	Search string
+	// Tags restricts the list to queries having all of these tags
+	Tags []string
+	// Order is a field name such as "name" or "-created_at" for descending order
+	Order string
+	// DataSourceID keeps only queries of this data source. Redash cannot
+	// filter on it, so it is applied client-side to each page.
+	DataSourceID int
+	// Archived lists archived queries only (/api/queries/archive)
+	Archived bool
+	// OnlyFavorites lists the API key user's favorite queries (/api/queries/favorites)
+	OnlyFavorites bool
+	// OnlyMine lists the queries created by the API key user (/api/queries/my)
+	OnlyMine bool
+	Page     int
+	PageSize int
+}
+
+func (o *QueryListOptions) path() (string, error) {
+	if o == nil {
+		return "/api/queries", nil
+	}
+
+	paths := []string{}
+	if o.Archived {
+		paths = append(paths, "/api/queries/archive")
+	}
+	if o.OnlyFavorites {
+		paths = append(paths, "/api/queries/favorites")
+	}
+	if o.OnlyMine {
+		paths = append(paths, "/api/queries/my")
 	}
+
+	switch len(paths) {
+	case 0:
+		return "/api/queries", nil
+	case 1:
+		return paths[0], nil
+	}
+	return "", fmt.Errorf("Only one of Archived, OnlyFavorites and OnlyMine can be set")
+}
+
+func (o *QueryListOptions) values() url.Values {
+	queryParams := url.Values{}
+	if o == nil {
+		return queryParams
+	}
+
+	if o.Search != "" {
+		queryParams.Set("q", o.Search)
+	}
+	for _, tag := range o.Tags {
+		queryParams.Add("tags", tag)
+	}
+	if o.Order != "" {
+		queryParams.Set("order", o.Order)
+	}
+	setPageValues(queryParams, o.Page, o.PageSize)
+
+	return queryParams
+}
+
+func (o *QueryListOptions) matches(query QuerySummary) bool {
+	return o == nil || o.DataSourceID == 0 || query.DataSourceID == o.DataSourceID
 }
 
 // Query models the response from Redash's /api/queries endpoint
@@ -58,18 +198,13 @@ type Query struct {
 	IsSafe            bool            `json:"is_safe"`
 	User              User            `json:"user"`
 	LastModifiedBy    User            `json:"last_modified_by"`
+	LastModifiedByID  int             `json:"last_modified_by_id,omitempty"`
 	IsFavorite        bool            `json:"is_favorite"`
 	CanEdit           bool            `json:"can_edit"`
 	Options           QueryOptions    `json:"options"`
 	Visualizations    []Visualization `json:"visualizations"`
-}
-
-// QuerySchedule struct
-type QuerySchedule struct {
-	Interval  int    `json:"interval"`
-	Time      string `json:"time,omitempty"`
-	DayOfWeek string `json:"day_of_week,omitempty"`
-	Until     string `json:"until,omitempty"`
+	RetrievedAt       time.Time       `json:"retrieved_at"`
+	Runtime           float32         `json:"runtime,omitempty"`
 }
 
 // QueryOptions struct
@@ -79,12 +214,22 @@ type QueryOptions struct {
 
 // QueryOptionsParameter struct
 type QueryOptionsParameter struct {
-	Title       string        `json:"title"`
-	Name        string        `json:"name"`
-	Type        string        `json:"type"`
-	EnumOptions string        `json:"enum_options"`
-	Locals      []interface{} `json:"locals"`
-	Value       interface{}   `json:"value"`
+	Title              string                   `json:"title"`
+	Name               string                   `json:"name"`
+	Type               string                   `json:"type"`
+	EnumOptions        string                   `json:"enum_options"`
+	QueryID            int                      `json:"queryId,omitempty"`
+	MultiValuesOptions *QueryMultiValuesOptions `json:"multiValuesOptions,omitempty"`
+	Locals             []interface{}            `json:"locals"`
+	Value              interface{}              `json:"value"`
+}
+
+// QueryMultiValuesOptions struct, set when a dropdown parameter allows
+// multiple values
+type QueryMultiValuesOptions struct {
+	Prefix    string `json:"prefix"`
+	Suffix    string `json:"suffix"`
+	Separator string `json:"separator"`
 }
 
 // QueryCreatePayload defines the schema for creating a new Redash query
@@ -113,12 +258,48 @@ type QueryPublishPayload struct {
 	Version int  `json:"version,omitempty"`
 }
 
-// GetQueries returns a paginated list of queries
+// GetQueries returns the first page of queries, see QueriesPager to iterate over all of them
 func (c *Client) GetQueries() (*QueriesList, error) {
-	path := "/api/queries"
+	return c.GetQueriesContext(context.Background())
+}
 
-	queryParams := url.Values{}
-	response, err := c.get(path, queryParams)
+// GetQueriesContext is like GetQueries but uses ctx for the underlying requests.
+func (c *Client) GetQueriesContext(ctx context.Context) (*QueriesList, error) {
+	return c.ListQueriesContext(ctx, nil)
+}
+
+// ListQueries returns a page of queries selected by the options
+func (c *Client) ListQueries(options *QueryListOptions) (*QueriesList, error) {
+	return c.ListQueriesContext(context.Background(), options)
+}
+
+// ListQueriesContext is like ListQueries but uses ctx for the underlying requests.
+func (c *Client) ListQueriesContext(ctx context.Context, options *QueryListOptions) (*QueriesList, error) {
+	queries, err := c.listQueries(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []QuerySummary{}
+	for _, query := range queries.Results {
+		if options.matches(query) {
+			results = append(results, query)
+		}
+	}
+	queries.Results = results
+
+	return queries, nil
+}
+
+// listQueries returns a page as sent by Redash, without client-side filtering
+func (c *Client) listQueries(ctx context.Context, options *QueryListOptions) (*QueriesList, error) {
+	path, err := options.path()
+	if err != nil {
+		return nil, err
+	}
+
+	queryParams := options.values()
+	response, err := c.get(ctx, path, queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -133,12 +314,92 @@ func (c *Client) GetQueries() (*QueriesList, error) {
 	return queries, nil
 }
 
+// SearchQueries returns the first page of queries whose name, description or
+// SQL text matches term. The legacy /api/queries/search endpoint redirects to
+// the same search, so it is not called directly.
+func (c *Client) SearchQueries(term string) (*QueriesList, error) {
+	return c.SearchQueriesContext(context.Background(), term)
+}
+
+// SearchQueriesContext is like SearchQueries but uses ctx for the underlying requests.
+func (c *Client) SearchQueriesContext(ctx context.Context, term string) (*QueriesList, error) {
+	return c.ListQueriesContext(ctx, &QueryListOptions{Search: term})
+}
+
+// GetRecentQueries returns the queries recently created or modified by the
+// API key user
+func (c *Client) GetRecentQueries() ([]QuerySummary, error) {
+	return c.GetRecentQueriesContext(context.Background())
+}
+
+// GetRecentQueriesContext is like GetRecentQueries but uses ctx for the underlying requests.
+func (c *Client) GetRecentQueriesContext(ctx context.Context) ([]QuerySummary, error) {
+	path := "/api/queries/recent"
+
+	response, err := c.get(ctx, path, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	queries := []QuerySummary{}
+	err = json.NewDecoder(response.Body).Decode(&queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return queries, nil
+}
+
+// QueriesPager iterates over every query selected by the options
+func (c *Client) QueriesPager(options *QueryListOptions) *Pager[QuerySummary] {
+	return c.QueriesPagerContext(context.Background(), options)
+}
+
+// QueriesPagerContext is like QueriesPager but uses ctx for the underlying requests.
+func (c *Client) QueriesPagerContext(ctx context.Context, options *QueryListOptions) *Pager[QuerySummary] {
+	pageOptions := QueryListOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+
+	pager := NewPager(ctx, pageOptions.Page, pageOptions.PageSize, func(ctx context.Context, page int) ([]QuerySummary, int, error) {
+		pageOptions.Page = page
+		queries, err := c.listQueries(ctx, &pageOptions)
+		if err != nil {
+			return nil, 0, err
+		}
+		return queries.Results, queries.Count, nil
+	})
+	if pageOptions.DataSourceID != 0 {
+		pager.filter = pageOptions.matches
+	}
+
+	return pager
+}
+
+// ListAllQueries returns every query selected by the options, up to limit
+// queries when limit is positive
+func (c *Client) ListAllQueries(options *QueryListOptions, limit int) ([]QuerySummary, error) {
+	return c.ListAllQueriesContext(context.Background(), options, limit)
+}
+
+// ListAllQueriesContext is like ListAllQueries but uses ctx for the underlying requests.
+func (c *Client) ListAllQueriesContext(ctx context.Context, options *QueryListOptions, limit int) ([]QuerySummary, error) {
+	return ListAll(c.QueriesPagerContext(ctx, options), limit)
+}
+
 // GetQuery gets a specific query
 func (c *Client) GetQuery(id int) (*Query, error) {
+	return c.GetQueryContext(context.Background(), id)
+}
+
+// GetQueryContext is like GetQuery but uses ctx for the underlying requests.
+func (c *Client) GetQueryContext(ctx context.Context, id int) (*Query, error) {
 	path := "/api/queries/" + strconv.Itoa(id)
 
 	queryParams := url.Values{}
-	response, err := c.get(path, queryParams)
+	response, err := c.get(ctx, path, queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +416,11 @@ func (c *Client) GetQuery(id int) (*Query, error) {
 
 // CreateQuery creates a new Redash query
 func (c *Client) CreateQuery(query *QueryCreatePayload) (*Query, error) {
+	return c.CreateQueryContext(context.Background(), query)
+}
+
+// CreateQueryContext is like CreateQuery but uses ctx for the underlying requests.
+func (c *Client) CreateQueryContext(ctx context.Context, query *QueryCreatePayload) (*Query, error) {
 	path := "/api/queries"
 
 	payload, err := json.Marshal(query)
@@ -163,7 +429,7 @@ func (c *Client) CreateQuery(query *QueryCreatePayload) (*Query, error) {
 	}
 
 	queryParams := url.Values{}
-	response, err := c.post(path, string(payload), queryParams)
+	response, err := c.post(ctx, path, string(payload), queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -178,8 +444,14 @@ func (c *Client) CreateQuery(query *QueryCreatePayload) (*Query, error) {
 	return newQuery, nil
 }
 
-// UpdateQuery updates an existing Redash query
+// UpdateQuery updates an existing Redash query. An update carrying a Version
+// is not retried on transient errors, see VersionConflictError.
 func (c *Client) UpdateQuery(id int, query *QueryUpdatePayload) (*Query, error) {
+	return c.UpdateQueryContext(context.Background(), id, query)
+}
+
+// UpdateQueryContext is like UpdateQuery but uses ctx for the underlying requests.
+func (c *Client) UpdateQueryContext(ctx context.Context, id int, query *QueryUpdatePayload) (*Query, error) {
 	path := "/api/queries/" + strconv.Itoa(id)
 
 	payload, err := json.Marshal(query)
@@ -187,15 +459,27 @@ func (c *Client) UpdateQuery(id int, query *QueryUpdatePayload) (*Query, error)
 		return nil, err
 	}
 
+	version := 0
+	if query != nil {
+		version = query.Version
+	}
+	if version != 0 {
+		ctx = withoutReplay(ctx)
+	}
+
 	queryParams := url.Values{}
-	response, err := c.post(path, string(payload), queryParams)
+	response, err := c.post(ctx, path, string(payload), queryParams)
 	if err != nil {
+		var apiError *APIError
+		if version != 0 && errors.As(err, &apiError) && IsConflict(apiError) {
+			return nil, &VersionConflictError{QueryID: id, Version: version, APIError: apiError}
+		}
 		return nil, err
 	}
 
 	defer response.Body.Close()
 	newQuery := new(Query)
-	json.NewDecoder(response.Body).Decode(newQuery)
+	err = json.NewDecoder(response.Body).Decode(newQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -204,6 +488,11 @@ func (c *Client) UpdateQuery(id int, query *QueryUpdatePayload) (*Query, error)
 }
 
 func (c *Client) PublishQuery(id int, query *QueryPublishPayload) (*Query, error) {
+	return c.PublishQueryContext(context.Background(), id, query)
+}
+
+// PublishQueryContext is like PublishQuery but uses ctx for the underlying requests.
+func (c *Client) PublishQueryContext(ctx context.Context, id int, query *QueryPublishPayload) (*Query, error) {
 	path := "/api/queries/" + strconv.Itoa(id)
 
 	payload, err := json.Marshal(query)
@@ -211,8 +500,12 @@ func (c *Client) PublishQuery(id int, query *QueryPublishPayload) (*Query, error
 		return nil, err
 	}
 
+	if query != nil && query.Version != 0 {
+		ctx = withoutReplay(ctx)
+	}
+
 	queryParams := url.Values{}
-	response, err := c.post(path, string(payload), queryParams)
+	response, err := c.post(ctx, path, string(payload), queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -229,9 +522,78 @@ func (c *Client) PublishQuery(id int, query *QueryPublishPayload) (*Query, error
 
 // ArchiveQuery archives an existing Redash query
 func (c *Client) ArchiveQuery(id int) error {
+	return c.ArchiveQueryContext(context.Background(), id)
+}
+
+// ArchiveQueryContext is like ArchiveQuery but uses ctx for the underlying requests.
+func (c *Client) ArchiveQueryContext(ctx context.Context, id int) error {
 	path := "/api/queries/" + strconv.Itoa(id)
 
-	_, err := c.delete(path, url.Values{})
+	response, err := c.delete(ctx, path, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ForkQuery creates a copy of a query, including its visualizations, owned by the current user
+func (c *Client) ForkQuery(id int) (*Query, error) {
+	return c.ForkQueryContext(context.Background(), id)
+}
+
+// ForkQueryContext is like ForkQuery but uses ctx for the underlying requests.
+func (c *Client) ForkQueryContext(ctx context.Context, id int) (*Query, error) {
+	path := "/api/queries/" + strconv.Itoa(id) + "/fork"
+
+	response, err := c.post(ctx, path, "", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	query := new(Query)
+	err = json.NewDecoder(response.Body).Decode(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// FavoriteQuery adds a query to the current user's favorites
+func (c *Client) FavoriteQuery(id int) error {
+	return c.FavoriteQueryContext(context.Background(), id)
+}
+
+// FavoriteQueryContext is like FavoriteQuery but uses ctx for the underlying requests.
+func (c *Client) FavoriteQueryContext(ctx context.Context, id int) error {
+	path := "/api/queries/" + strconv.Itoa(id) + "/favorite"
+
+	response, err := c.post(ctx, path, "", url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// UnfavoriteQuery removes a query from the current user's favorites
+func (c *Client) UnfavoriteQuery(id int) error {
+	return c.UnfavoriteQueryContext(context.Background(), id)
+}
+
+// UnfavoriteQueryContext is like UnfavoriteQuery but uses ctx for the underlying requests.
+func (c *Client) UnfavoriteQueryContext(ctx context.Context, id int) error {
+	path := "/api/queries/" + strconv.Itoa(id) + "/favorite"
+
+	response, err := c.delete(ctx, path, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
 
-	return err
+	return nil
 }
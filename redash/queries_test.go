@@ -1,7 +1,9 @@
 package redash
 
 import (
+	"context"
 	"io/ioutil"
+	"net/http"
 	"testing"
 	"time"
 
@@ -32,6 +34,156 @@ func TestGetQueries(t *testing.T) {
 	assert.Equal(3, len(queries.Results))
 }
 
+func TestQueriesPager(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries", "page=1&page_size=2",
+		httpmock.NewStringResponder(200, `{"count": 5, "page": 1, "page_size": 2, "results": [{"id": 1}, {"id": 2}]}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries", "page=2&page_size=2",
+		httpmock.NewStringResponder(200, `{"count": 5, "page": 2, "page_size": 2, "results": [{"id": 3}, {"id": 4}]}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries", "page=3&page_size=2",
+		httpmock.NewStringResponder(200, `{"count": 5, "page": 3, "page_size": 2, "results": [{"id": 5}]}`))
+
+	var ids []int
+	pager := c.QueriesPager(&QueryListOptions{PageSize: 2})
+	for pager.Next() {
+		ids = append(ids, pager.Item().ID)
+	}
+	assert.Nil(pager.Err())
+	assert.Equal([]int{1, 2, 3, 4, 5}, ids)
+	assert.Equal(5, pager.Count())
+
+	queries, err := c.ListAllQueries(&QueryListOptions{PageSize: 2}, 3)
+	assert.Nil(err)
+	assert.Equal(3, len(queries))
+	assert.Equal(5, httpmock.GetTotalCallCount())
+}
+
+func TestQueriesPagerFromLaterPage(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries", "page=2&page_size=2",
+		httpmock.NewStringResponder(200, `{"count": 4, "page": 2, "page_size": 2, "results": [{"id": 3}, {"id": 4}]}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries", "page=3&page_size=2",
+		httpmock.NewStringResponder(400, `{"message": "Page is out of range"}`))
+
+	queries, err := c.ListAllQueries(&QueryListOptions{Page: 2, PageSize: 2}, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(queries))
+	assert.Equal(4, queries[1].ID)
+	assert.Equal(1, httpmock.GetTotalCallCount())
+}
+
+func TestQuerySummaryConversion(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	body, err := ioutil.ReadFile("testdata/get-queries.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries",
+		httpmock.NewStringResponder(200, string(body)))
+
+	queries, err := c.GetQueries()
+	assert.Nil(err)
+
+	summary := queries.Results[0]
+	query := summary.ToQuery()
+	assert.Equal(summary.ID, query.ID)
+	assert.Equal(summary.Name, query.Name)
+	assert.Equal(summary.Query, query.Query)
+	assert.Equal(summary.Runtime, query.Runtime)
+	assert.Equal(summary.LastModifiedByID, query.LastModifiedByID)
+	assert.Equal(summary.Options, query.Options)
+	assert.Nil(query.Visualizations)
+
+	assert.Equal(summary, query.Summary())
+}
+
+func TestListQueries(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	page := `{"count": 3, "page": 1, "page_size": 50, "results": [
+		{"id": 1, "data_source_id": 1}, {"id": 2, "data_source_id": 2}, {"id": 3, "data_source_id": 1}
+	]}`
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries",
+		"q=events&tags=prod&tags=etl&order=-created_at&page_size=50",
+		httpmock.NewStringResponder(200, page))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries",
+		"q=events&tags=prod&tags=etl&order=-created_at&page=1&page_size=50",
+		httpmock.NewStringResponder(200, page))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries/archive", "q=events",
+		httpmock.NewStringResponder(200, `{"count": 1, "page": 1, "page_size": 25, "results": [{"id": 4, "is_archived": true}]}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries/favorites", "",
+		httpmock.NewStringResponder(200, `{"count": 1, "page": 1, "page_size": 25, "results": [{"id": 5, "is_favorite": true}]}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/queries/my", "",
+		httpmock.NewStringResponder(200, `{"count": 1, "page": 1, "page_size": 25, "results": [{"id": 6}]}`))
+
+	options := &QueryListOptions{
+		Search:       "events",
+		Tags:         []string{"prod", "etl"},
+		Order:        "-created_at",
+		DataSourceID: 1,
+		PageSize:     50,
+	}
+	queries, err := c.ListQueries(options)
+	assert.Nil(err)
+	assert.Equal(3, queries.Count)
+	assert.Equal(2, len(queries.Results))
+
+	all, err := c.ListAllQueries(options, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(all))
+	assert.Equal(3, all[1].ID)
+
+	queries, err = c.ListQueries(&QueryListOptions{Search: "events", Archived: true})
+	assert.Nil(err)
+	assert.True(queries.Results[0].IsArchived)
+
+	queries, err = c.ListQueries(&QueryListOptions{OnlyFavorites: true})
+	assert.Nil(err)
+	assert.Equal(5, queries.Results[0].ID)
+
+	queries, err = c.ListQueries(&QueryListOptions{OnlyMine: true})
+	assert.Nil(err)
+	assert.Equal(6, queries.Results[0].ID)
+
+	_, err = c.ListQueries(&QueryListOptions{OnlyMine: true, Archived: true})
+	assert.EqualError(err, "Only one of Archived, OnlyFavorites and OnlyMine can be set")
+}
+
+func TestGetRecentQueries(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/recent",
+		httpmock.NewStringResponder(200, `[{"id": 9, "name": "Recent"}]`))
+
+	queries, err := c.GetRecentQueries()
+	assert.Nil(err)
+	assert.Equal(1, len(queries))
+	assert.Equal("Recent", queries[0].Name)
+}
+
 func TestGetQuery(t *testing.T) {
 	assert := assert.New(t)
 	httpmock.Activate()
@@ -85,6 +237,27 @@ func TestGetQuery(t *testing.T) {
 	assert.Equal("DAU", queryVisualisation2.Name)
 }
 
+func TestGetQueryContextDeadline(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/1",
+		func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	query, err := c.GetQueryContext(ctx, 1)
+	assert.Nil(query)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+}
+
 func TestCreateQuery(t *testing.T) {
 	assert := assert.New(t)
 	httpmock.Activate()
@@ -150,3 +323,39 @@ func TestArchiveQuery(t *testing.T) {
 	err := c.ArchiveQuery(5)
 	assert.Nil(err)
 }
+
+func TestForkQuery(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5/fork",
+		httpmock.NewStringResponder(200, `{"id": 6, "name": "Copy of (#5) Users", "query": "SELECT 1;", "data_source_id": 1, "version": 1}`))
+
+	query, err := c.ForkQuery(5)
+	assert.Nil(err)
+	assert.Equal(6, query.ID)
+	assert.Equal("Copy of (#5) Users", query.Name)
+}
+
+func TestFavoriteQuery(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5/favorite",
+		httpmock.NewStringResponder(200, "{}"))
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/queries/5/favorite",
+		httpmock.NewStringResponder(200, "{}"))
+
+	assert.Nil(c.FavoriteQuery(5))
+	assert.Nil(c.UnfavoriteQuery(5))
+
+	info := httpmock.GetCallCountInfo()
+	assert.Equal(1, info["POST https://com.acme/api/queries/5/favorite"])
+	assert.Equal(1, info["DELETE https://com.acme/api/queries/5/favorite"])
+}
@@ -0,0 +1,271 @@
+package redash
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Query parameter types supported by Redash
+const (
+	ParameterTypeText                     = "text"
+	ParameterTypeNumber                   = "number"
+	ParameterTypeEnum                     = "enum"
+	ParameterTypeQuery                    = "query"
+	ParameterTypeDate                     = "date"
+	ParameterTypeDateTimeLocal            = "datetime-local"
+	ParameterTypeDateTimeWithSeconds      = "datetime-with-seconds"
+	ParameterTypeDateRange                = "date-range"
+	ParameterTypeDateTimeRange            = "datetime-range"
+	ParameterTypeDateTimeRangeWithSeconds = "datetime-range-with-seconds"
+)
+
+// parameterTimeLayouts are the formats Redash expects for date parameters
+var parameterTimeLayouts = map[string]string{
+	ParameterTypeDate:                     "2006-01-02",
+	ParameterTypeDateTimeLocal:            "2006-01-02 15:04",
+	ParameterTypeDateTimeWithSeconds:      "2006-01-02 15:04:05",
+	ParameterTypeDateRange:                "2006-01-02",
+	ParameterTypeDateTimeRange:            "2006-01-02 15:04",
+	ParameterTypeDateTimeRangeWithSeconds: "2006-01-02 15:04:05",
+}
+
+// ParameterValue is a typed value for a query parameter. Use TextValue,
+// NumberValue, DateValue, DateTimeValue, DateRangeValue, DateTimeRangeValue,
+// EnumValue or QueryDropdownValue to build one.
+type ParameterValue interface {
+	// encode validates the value against the parameter definition and returns
+	// its JSON representation
+	encode(parameter QueryOptionsParameter) (interface{}, error)
+}
+
+// ParameterValues maps parameter names to their typed values
+type ParameterValues map[string]ParameterValue
+
+type textValue string
+
+// TextValue builds a value for a text parameter
+func TextValue(value string) ParameterValue {
+	return textValue(value)
+}
+
+func (v textValue) encode(parameter QueryOptionsParameter) (interface{}, error) {
+	if parameter.Type != ParameterTypeText {
+		return nil, incompatibleParameterError(parameter, "text")
+	}
+	return string(v), nil
+}
+
+type numberValue float64
+
+// NumberValue builds a value for a number parameter
+func NumberValue(value float64) ParameterValue {
+	return numberValue(value)
+}
+
+func (v numberValue) encode(parameter QueryOptionsParameter) (interface{}, error) {
+	if parameter.Type != ParameterTypeNumber {
+		return nil, incompatibleParameterError(parameter, "number")
+	}
+	return float64(v), nil
+}
+
+type dateValue struct {
+	value    time.Time
+	withTime bool
+}
+
+// DateValue builds a value for a date parameter
+func DateValue(value time.Time) ParameterValue {
+	return dateValue{value: value}
+}
+
+// DateTimeValue builds a value for a datetime-local or datetime-with-seconds parameter
+func DateTimeValue(value time.Time) ParameterValue {
+	return dateValue{value: value, withTime: true}
+}
+
+func (v dateValue) encode(parameter QueryOptionsParameter) (interface{}, error) {
+	switch {
+	case !v.withTime && parameter.Type == ParameterTypeDate:
+	case v.withTime && (parameter.Type == ParameterTypeDateTimeLocal || parameter.Type == ParameterTypeDateTimeWithSeconds):
+	default:
+		if v.withTime {
+			return nil, incompatibleParameterError(parameter, "datetime")
+		}
+		return nil, incompatibleParameterError(parameter, "date")
+	}
+	return v.value.Format(parameterTimeLayouts[parameter.Type]), nil
+}
+
+type dateRangeValue struct {
+	start    time.Time
+	end      time.Time
+	withTime bool
+}
+
+// DateRangeValue builds a value for a date-range parameter
+func DateRangeValue(start, end time.Time) ParameterValue {
+	return dateRangeValue{start: start, end: end}
+}
+
+// DateTimeRangeValue builds a value for a datetime-range or
+// datetime-range-with-seconds parameter
+func DateTimeRangeValue(start, end time.Time) ParameterValue {
+	return dateRangeValue{start: start, end: end, withTime: true}
+}
+
+func (v dateRangeValue) encode(parameter QueryOptionsParameter) (interface{}, error) {
+	switch {
+	case !v.withTime && parameter.Type == ParameterTypeDateRange:
+	case v.withTime && (parameter.Type == ParameterTypeDateTimeRange || parameter.Type == ParameterTypeDateTimeRangeWithSeconds):
+	default:
+		if v.withTime {
+			return nil, incompatibleParameterError(parameter, "datetime range")
+		}
+		return nil, incompatibleParameterError(parameter, "date range")
+	}
+	if v.end.Before(v.start) {
+		return nil, fmt.Errorf("parameter %q: range end is before its start", parameter.Name)
+	}
+
+	layout := parameterTimeLayouts[parameter.Type]
+	return map[string]string{
+		"start": v.start.Format(layout),
+		"end":   v.end.Format(layout),
+	}, nil
+}
+
+type enumValue []string
+
+// EnumValue builds a value for a dropdown list parameter. Several values may
+// be given when the parameter allows multiple values.
+func EnumValue(values ...string) ParameterValue {
+	return enumValue(values)
+}
+
+func (v enumValue) encode(parameter QueryOptionsParameter) (interface{}, error) {
+	if parameter.Type != ParameterTypeEnum {
+		return nil, incompatibleParameterError(parameter, "enum")
+	}
+
+	options := map[string]bool{}
+	for _, option := range strings.Split(parameter.EnumOptions, "\n") {
+		options[strings.TrimSpace(option)] = true
+	}
+	for _, value := range v {
+		if !options[value] {
+			return nil, fmt.Errorf("parameter %q: %q is not one of the dropdown options", parameter.Name, value)
+		}
+	}
+
+	return encodeMultiValue(parameter, v)
+}
+
+type queryDropdownValue []string
+
+// QueryDropdownValue builds a value for a query based dropdown list
+// parameter. Several values may be given when the parameter allows multiple
+// values.
+func QueryDropdownValue(values ...string) ParameterValue {
+	return queryDropdownValue(values)
+}
+
+func (v queryDropdownValue) encode(parameter QueryOptionsParameter) (interface{}, error) {
+	if parameter.Type != ParameterTypeQuery {
+		return nil, incompatibleParameterError(parameter, "query dropdown")
+	}
+	return encodeMultiValue(parameter, v)
+}
+
+func encodeMultiValue(parameter QueryOptionsParameter, values []string) (interface{}, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("parameter %q: no value given", parameter.Name)
+	}
+	if parameter.MultiValuesOptions != nil {
+		return values, nil
+	}
+	if len(values) > 1 {
+		return nil, fmt.Errorf("parameter %q does not allow multiple values", parameter.Name)
+	}
+	return values[0], nil
+}
+
+func incompatibleParameterError(parameter QueryOptionsParameter, valueType string) error {
+	return fmt.Errorf("parameter %q of type %s cannot take a %s value", parameter.Name, parameter.Type, valueType)
+}
+
+// EncodeParameters validates values against the parameter definitions of the
+// query and returns them in the form expected by the Redash API. Parameters
+// without a value fall back to the default saved with the query.
+func (q *Query) EncodeParameters(values ParameterValues) (map[string]interface{}, error) {
+	definitions := map[string]QueryOptionsParameter{}
+	for _, parameter := range q.Options.Parameters {
+		definitions[parameter.Name] = parameter
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	encoded := map[string]interface{}{}
+	for _, name := range names {
+		parameter, exists := definitions[name]
+		if !exists {
+			return nil, fmt.Errorf("query %d has no parameter %q", q.ID, name)
+		}
+		if values[name] == nil {
+			return nil, fmt.Errorf("parameter %q: no value given", name)
+		}
+
+		value, err := values[name].encode(parameter)
+		if err != nil {
+			return nil, err
+		}
+		encoded[name] = value
+	}
+
+	for _, parameter := range q.Options.Parameters {
+		if _, exists := encoded[parameter.Name]; exists {
+			continue
+		}
+		if parameter.Value == nil {
+			return nil, fmt.Errorf("parameter %q: no value given and no default", parameter.Name)
+		}
+		encoded[parameter.Name] = parameter.Value
+	}
+
+	return encoded, nil
+}
+
+// ExecuteQueryWithParameters validates typed parameter values against the
+// query definition, then runs the query like ExecuteQuery. Use
+// ExecuteQueryOptions.MaxAge to accept a cached result.
+func (c *Client) ExecuteQueryWithParameters(id int, values ParameterValues, options *ExecuteQueryOptions) (*QueryResult, error) {
+	return c.ExecuteQueryWithParametersContext(context.Background(), id, values, options)
+}
+
+// ExecuteQueryWithParametersContext is like ExecuteQueryWithParameters but uses ctx for the underlying requests.
+func (c *Client) ExecuteQueryWithParametersContext(ctx context.Context, id int, values ParameterValues, options *ExecuteQueryOptions) (*QueryResult, error) {
+	query, err := c.GetQueryContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters, err := query.EncodeParameters(values)
+	if err != nil {
+		return nil, err
+	}
+
+	executeOptions := ExecuteQueryOptions{}
+	if options != nil {
+		executeOptions = *options
+	}
+	executeOptions.Parameters = parameters
+
+	return c.ExecuteQueryContext(ctx, id, &executeOptions)
+}
@@ -0,0 +1,108 @@
+package redash
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func parameterizedQuery() *Query {
+	return &Query{
+		ID: 7,
+		Options: QueryOptions{Parameters: []QueryOptionsParameter{
+			{Name: "name", Type: ParameterTypeText, Value: "everyone"},
+			{Name: "limit", Type: ParameterTypeNumber, Value: 10.0},
+			{Name: "day", Type: ParameterTypeDate},
+			{Name: "since", Type: ParameterTypeDateTimeWithSeconds, Value: "2023-01-01 00:00:00"},
+			{Name: "period", Type: ParameterTypeDateRange, Value: map[string]interface{}{"start": "2023-01-01", "end": "2023-01-31"}},
+			{Name: "country", Type: ParameterTypeEnum, EnumOptions: "FR\nJP\nUS", Value: "FR"},
+			{Name: "apps", Type: ParameterTypeQuery, QueryID: 3, MultiValuesOptions: &QueryMultiValuesOptions{Prefix: "'", Suffix: "'", Separator: ","}, Value: []interface{}{"web"}},
+		}},
+	}
+}
+
+func TestEncodeParameters(t *testing.T) {
+	assert := assert.New(t)
+	query := parameterizedQuery()
+
+	day := time.Date(2023, 1, 3, 15, 4, 5, 0, time.UTC)
+	encoded, err := query.EncodeParameters(ParameterValues{
+		"name":    TextValue("Alice"),
+		"limit":   NumberValue(25),
+		"day":     DateValue(day),
+		"since":   DateTimeValue(day),
+		"period":  DateRangeValue(day, day.AddDate(0, 0, 7)),
+		"country": EnumValue("JP"),
+		"apps":    QueryDropdownValue("web", "ios"),
+	})
+	assert.Nil(err)
+	assert.Equal(map[string]interface{}{
+		"name":    "Alice",
+		"limit":   25.0,
+		"day":     "2023-01-03",
+		"since":   "2023-01-03 15:04:05",
+		"period":  map[string]string{"start": "2023-01-03", "end": "2023-01-10"},
+		"country": "JP",
+		"apps":    []string{"web", "ios"},
+	}, encoded)
+
+	// missing values fall back to the saved defaults
+	encoded, err = query.EncodeParameters(ParameterValues{"day": DateValue(day)})
+	assert.Nil(err)
+	assert.Equal("everyone", encoded["name"])
+	assert.Equal("FR", encoded["country"])
+
+	_, err = query.EncodeParameters(ParameterValues{})
+	assert.EqualError(err, `parameter "day": no value given and no default`)
+
+	_, err = query.EncodeParameters(ParameterValues{"day": DateValue(day), "unknown": TextValue("x")})
+	assert.EqualError(err, `query 7 has no parameter "unknown"`)
+
+	_, err = query.EncodeParameters(ParameterValues{"day": TextValue("yesterday")})
+	assert.EqualError(err, `parameter "day" of type date cannot take a text value`)
+
+	_, err = query.EncodeParameters(ParameterValues{"day": DateValue(day), "country": EnumValue("DE")})
+	assert.EqualError(err, `parameter "country": "DE" is not one of the dropdown options`)
+
+	_, err = query.EncodeParameters(ParameterValues{"day": DateValue(day), "country": EnumValue("FR", "JP")})
+	assert.EqualError(err, `parameter "country" does not allow multiple values`)
+
+	_, err = query.EncodeParameters(ParameterValues{"day": DateValue(day), "period": DateRangeValue(day, day.AddDate(0, 0, -1))})
+	assert.EqualError(err, `parameter "period": range end is before its start`)
+}
+
+func TestExecuteQueryWithParameters(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	query, _ := json.Marshal(parameterizedQuery())
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/7",
+		httpmock.NewBytesResponder(200, query))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/7/results",
+		func(request *http.Request) (*http.Response, error) {
+			payload := QueryExecutePayload{}
+			assert.Nil(json.NewDecoder(request.Body).Decode(&payload))
+			assert.Equal(600, payload.MaxAge)
+			assert.Equal("2023-01-03", payload.Parameters["day"])
+			assert.Equal(10.0, payload.Parameters["limit"])
+			return httpmock.NewStringResponse(200, `{"query_result": {"id": 42}}`), nil
+		})
+
+	result, err := c.ExecuteQueryWithParameters(7, ParameterValues{
+		"day": DateValue(time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)),
+	}, &ExecuteQueryOptions{MaxAge: 600})
+	assert.Nil(err)
+	assert.Equal(42, result.ID)
+
+	// invalid values are rejected before anything is executed
+	_, err = c.ExecuteQueryWithParameters(7, ParameterValues{"day": NumberValue(1)}, nil)
+	assert.NotNil(err)
+	assert.Equal(1, httpmock.GetCallCountInfo()["POST https://com.acme/api/queries/7/results"])
+}
@@ -0,0 +1,452 @@
+package redash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultJobPollInterval is how often ExecuteQuery polls a running job
+const DefaultJobPollInterval = time.Second
+
+// jobCancelTimeout bounds the request cancelling a job once the caller's own
+// context is already done
+const jobCancelTimeout = 10 * time.Second
+
+// JobStatus mirrors the numeric job states of Redash
+type JobStatus int
+
+// Job statuses as returned by /api/jobs/{id}
+const (
+	JobStatusPending   JobStatus = 1
+	JobStatusStarted   JobStatus = 2
+	JobStatusSuccess   JobStatus = 3
+	JobStatusFailure   JobStatus = 4
+	JobStatusCancelled JobStatus = 5
+)
+
+// Done returns true once the job will not change state anymore
+func (s JobStatus) Done() bool {
+	return s == JobStatusSuccess || s == JobStatusFailure || s == JobStatusCancelled
+}
+
+func (s JobStatus) String() string {
+	switch s {
+	case JobStatusPending:
+		return "pending"
+	case JobStatusStarted:
+		return "started"
+	case JobStatusSuccess:
+		return "success"
+	case JobStatusFailure:
+		return "failure"
+	case JobStatusCancelled:
+		return "cancelled"
+	}
+	return "unknown (" + strconv.Itoa(int(s)) + ")"
+}
+
+// Column types used by Redash query results
+const (
+	ColumnTypeInteger  = "integer"
+	ColumnTypeFloat    = "float"
+	ColumnTypeBoolean  = "boolean"
+	ColumnTypeString   = "string"
+	ColumnTypeDatetime = "datetime"
+	ColumnTypeDate     = "date"
+)
+
+// Job models a query execution job
+type Job struct {
+	ID            string    `json:"id"`
+	Status        JobStatus `json:"status"`
+	Error         string    `json:"error"`
+	QueryResultID int       `json:"query_result_id"`
+	UpdatedAt     float64   `json:"updated_at"`
+}
+
+// QueryResult models the response from Redash's /api/query_results/{id} endpoint
+type QueryResult struct {
+	ID           int             `json:"id"`
+	QueryHash    string          `json:"query_hash"`
+	Query        string          `json:"query"`
+	Data         QueryResultData `json:"data"`
+	DataSourceID int             `json:"data_source_id"`
+	Runtime      float64         `json:"runtime"`
+	RetrievedAt  time.Time       `json:"retrieved_at"`
+}
+
+// QueryResultData holds the columns and rows of a QueryResult
+type QueryResultData struct {
+	Columns []QueryResultColumn      `json:"columns"`
+	Rows    []map[string]interface{} `json:"rows"`
+}
+
+// QueryResultColumn describes a single column of a QueryResult
+type QueryResultColumn struct {
+	Name         string `json:"name"`
+	FriendlyName string `json:"friendly_name"`
+	Type         string `json:"type"`
+}
+
+// QueryExecution is returned when submitting a query: Redash answers with
+// either a cached QueryResult or a Job to poll
+type QueryExecution struct {
+	Job         *Job         `json:"job,omitempty"`
+	QueryResult *QueryResult `json:"query_result,omitempty"`
+}
+
+// AdhocQueryPayload defines the schema for running SQL that is not saved as a query
+type AdhocQueryPayload struct {
+	DataSourceID int                    `json:"data_source_id"`
+	Query        string                 `json:"query"`
+	QueryID      int                    `json:"query_id,omitempty"`
+	MaxAge       int                    `json:"max_age"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// QueryExecutePayload defines the schema for running a saved query
+type QueryExecutePayload struct {
+	MaxAge     int                    `json:"max_age"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ExecuteQueryOptions controls how ExecuteQuery runs and waits for a query
+type ExecuteQueryOptions struct {
+	// MaxAge in seconds allows Redash to return a cached result younger than
+	// this. Zero always runs the query.
+	MaxAge int
+	// Parameters are sent as-is with the execution request
+	Parameters map[string]interface{}
+	// PollInterval between job status checks, DefaultJobPollInterval if zero
+	PollInterval time.Duration
+	// Timeout bounds the whole execution. Zero relies on the context only.
+	Timeout time.Duration
+}
+
+// JobError is returned when a job finishes without a result
+type JobError struct {
+	Job *Job
+}
+
+func (e *JobError) Error() string {
+	if e.Job.Error != "" {
+		return fmt.Sprintf("job %s %s: %s", e.Job.ID, e.Job.Status, e.Job.Error)
+	}
+	return fmt.Sprintf("job %s %s", e.Job.ID, e.Job.Status)
+}
+
+// SubmitAdhocQuery runs SQL against a data source via /api/query_results
+func (c *Client) SubmitAdhocQuery(adhocQueryPayload *AdhocQueryPayload) (*QueryExecution, error) {
+	return c.SubmitAdhocQueryContext(context.Background(), adhocQueryPayload)
+}
+
+// SubmitAdhocQueryContext is like SubmitAdhocQuery but uses ctx for the underlying requests.
+func (c *Client) SubmitAdhocQueryContext(ctx context.Context, adhocQueryPayload *AdhocQueryPayload) (*QueryExecution, error) {
+	path := "/api/query_results"
+
+	payload, err := json.Marshal(adhocQueryPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.submitExecution(ctx, path, string(payload))
+}
+
+// SubmitQuery runs a saved query via /api/queries/{id}/results
+func (c *Client) SubmitQuery(id int, queryExecutePayload *QueryExecutePayload) (*QueryExecution, error) {
+	return c.SubmitQueryContext(context.Background(), id, queryExecutePayload)
+}
+
+// SubmitQueryContext is like SubmitQuery but uses ctx for the underlying requests.
+func (c *Client) SubmitQueryContext(ctx context.Context, id int, queryExecutePayload *QueryExecutePayload) (*QueryExecution, error) {
+	path := "/api/queries/" + strconv.Itoa(id) + "/results"
+
+	payload, err := json.Marshal(queryExecutePayload)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.submitExecution(ctx, path, string(payload))
+}
+
+func (c *Client) submitExecution(ctx context.Context, path, payload string) (*QueryExecution, error) {
+	response, err := c.post(ctx, path, payload, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	execution := new(QueryExecution)
+	err = json.NewDecoder(response.Body).Decode(execution)
+	if err != nil {
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+// GetJob gets the status of a query execution job
+func (c *Client) GetJob(id string) (*Job, error) {
+	return c.GetJobContext(context.Background(), id)
+}
+
+// GetJobContext is like GetJob but uses ctx for the underlying requests.
+func (c *Client) GetJobContext(ctx context.Context, id string) (*Job, error) {
+	path := "/api/jobs/" + id
+
+	response, err := c.get(ctx, path, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	jobResponse := struct {
+		Job Job `json:"job"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&jobResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jobResponse.Job, nil
+}
+
+// CancelJob cancels a running query execution job
+func (c *Client) CancelJob(id string) error {
+	return c.CancelJobContext(context.Background(), id)
+}
+
+// CancelJobContext is like CancelJob but uses ctx for the underlying requests.
+func (c *Client) CancelJobContext(ctx context.Context, id string) error {
+	path := "/api/jobs/" + id
+
+	response, err := c.delete(ctx, path, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// GetQueryResult gets a specific query result
+func (c *Client) GetQueryResult(id int) (*QueryResult, error) {
+	return c.GetQueryResultContext(context.Background(), id)
+}
+
+// GetQueryResultContext is like GetQueryResult but uses ctx for the underlying requests.
+func (c *Client) GetQueryResultContext(ctx context.Context, id int) (*QueryResult, error) {
+	path := "/api/query_results/" + strconv.Itoa(id)
+
+	response, err := c.get(ctx, path, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	resultResponse := struct {
+		QueryResult QueryResult `json:"query_result"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&resultResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resultResponse.QueryResult, nil
+}
+
+// WaitForJob polls a job until it is done and returns its final state. A job
+// that failed or was cancelled is reported as a *JobError.
+func (c *Client) WaitForJob(id string, pollInterval time.Duration) (*Job, error) {
+	return c.WaitForJobContext(context.Background(), id, pollInterval)
+}
+
+// WaitForJobContext is like WaitForJob but stops polling once ctx is done,
+// cancelling the job so it isn't left running on Redash.
+func (c *Client) WaitForJobContext(ctx context.Context, id string, pollInterval time.Duration) (*Job, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultJobPollInterval
+	}
+
+	for {
+		job, err := c.GetJobContext(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.cancelAbandonedJob(id)
+			}
+			return nil, err
+		}
+
+		switch job.Status {
+		case JobStatusSuccess:
+			return job, nil
+		case JobStatusFailure, JobStatusCancelled:
+			return job, &JobError{Job: job}
+		}
+
+		if err := sleepContext(ctx, pollInterval); err != nil {
+			c.cancelAbandonedJob(id)
+			return job, fmt.Errorf("job %s did not finish: %w", id, err)
+		}
+	}
+}
+
+// cancelAbandonedJob cancels a job nobody waits for anymore. The caller's
+// context is already done, so the request gets its own short deadline.
+func (c *Client) cancelAbandonedJob(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobCancelTimeout)
+	defer cancel()
+
+	if err := c.CancelJobContext(ctx, id); err != nil {
+		log.Warn(fmt.Sprintf("[WARN] Could not cancel job %s: %s", id, err))
+	}
+}
+
+// ExecuteQuery runs a saved query, waits for the job to finish and returns
+// its result
+func (c *Client) ExecuteQuery(id int, options *ExecuteQueryOptions) (*QueryResult, error) {
+	return c.ExecuteQueryContext(context.Background(), id, options)
+}
+
+// ExecuteQueryContext is like ExecuteQuery but uses ctx for the underlying requests.
+func (c *Client) ExecuteQueryContext(ctx context.Context, id int, options *ExecuteQueryOptions) (*QueryResult, error) {
+	if options == nil {
+		options = &ExecuteQueryOptions{}
+	}
+
+	return c.execute(ctx, options, func(ctx context.Context) (*QueryExecution, error) {
+		return c.SubmitQueryContext(ctx, id, &QueryExecutePayload{
+			MaxAge:     options.MaxAge,
+			Parameters: options.Parameters,
+		})
+	})
+}
+
+// ExecuteAdhocQuery runs SQL against a data source, waits for the job to
+// finish and returns its result
+func (c *Client) ExecuteAdhocQuery(dataSourceID int, query string, options *ExecuteQueryOptions) (*QueryResult, error) {
+	return c.ExecuteAdhocQueryContext(context.Background(), dataSourceID, query, options)
+}
+
+// ExecuteAdhocQueryContext is like ExecuteAdhocQuery but uses ctx for the underlying requests.
+func (c *Client) ExecuteAdhocQueryContext(ctx context.Context, dataSourceID int, query string, options *ExecuteQueryOptions) (*QueryResult, error) {
+	if options == nil {
+		options = &ExecuteQueryOptions{}
+	}
+
+	return c.execute(ctx, options, func(ctx context.Context) (*QueryExecution, error) {
+		return c.SubmitAdhocQueryContext(ctx, &AdhocQueryPayload{
+			DataSourceID: dataSourceID,
+			Query:        query,
+			MaxAge:       options.MaxAge,
+			Parameters:   options.Parameters,
+		})
+	})
+}
+
+func (c *Client) execute(ctx context.Context, options *ExecuteQueryOptions, submit func(context.Context) (*QueryExecution, error)) (*QueryResult, error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	execution, err := submit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if execution.QueryResult != nil {
+		return execution.QueryResult, nil
+	}
+	if execution.Job == nil {
+		return nil, fmt.Errorf("Redash returned neither a job nor a query result")
+	}
+
+	job, err := c.WaitForJobContext(ctx, execution.Job.ID, options.PollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetQueryResultContext(ctx, job.QueryResultID)
+}
+
+// ResultFormat is a file format query results can be exported to
+type ResultFormat string
+
+// Export formats supported by Redash
+const (
+	ResultFormatCSV  ResultFormat = "csv"
+	ResultFormatTSV  ResultFormat = "tsv"
+	ResultFormatJSON ResultFormat = "json"
+	ResultFormatXLSX ResultFormat = "xlsx"
+)
+
+func (f ResultFormat) valid() bool {
+	switch f {
+	case ResultFormatCSV, ResultFormatTSV, ResultFormatJSON, ResultFormatXLSX:
+		return true
+	}
+	return false
+}
+
+// DownloadQueryResult streams a query result in the given format. The body is
+// not buffered, the caller must close the returned reader.
+func (c *Client) DownloadQueryResult(queryID, resultID int, format ResultFormat) (io.ReadCloser, error) {
+	return c.DownloadQueryResultContext(context.Background(), queryID, resultID, format)
+}
+
+// DownloadQueryResultContext is like DownloadQueryResult but uses ctx for the underlying requests.
+func (c *Client) DownloadQueryResultContext(ctx context.Context, queryID, resultID int, format ResultFormat) (io.ReadCloser, error) {
+	if !format.valid() {
+		return nil, fmt.Errorf("Unsupported result format: %s", format)
+	}
+
+	path := "/api/queries/" + strconv.Itoa(queryID) + "/results/" + strconv.Itoa(resultID) + "." + string(format)
+
+	response, err := c.get(ctx, path, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+// DownloadQueryResultToFile writes a query result in the given format to a
+// file, replacing it if it already exists
+func (c *Client) DownloadQueryResultToFile(queryID, resultID int, format ResultFormat, filename string) error {
+	return c.DownloadQueryResultToFileContext(context.Background(), queryID, resultID, format, filename)
+}
+
+// DownloadQueryResultToFileContext is like DownloadQueryResultToFile but uses ctx for the underlying requests.
+func (c *Client) DownloadQueryResultToFileContext(ctx context.Context, queryID, resultID int, format ResultFormat, filename string) error {
+	body, err := c.DownloadQueryResultContext(ctx, queryID, resultID, format)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filename)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package redash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetQueryResult(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	body, err := ioutil.ReadFile("testdata/get-query-result.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("GET", "https://com.acme/api/query_results/42",
+		httpmock.NewStringResponder(200, string(body)))
+
+	result, err := c.GetQueryResult(42)
+	assert.Nil(err)
+
+	assert.Equal(42, result.ID)
+	assert.Equal(2, result.DataSourceID)
+	assert.Equal(0.153, result.Runtime)
+	expectedRetrievedAt, _ := time.Parse(time.RFC3339, "2023-01-03T02:57:24.435Z")
+	assert.Equal(expectedRetrievedAt, result.RetrievedAt)
+	assert.Equal([]QueryResultColumn{
+		{Name: "name", FriendlyName: "name", Type: ColumnTypeString},
+		{Name: "events", FriendlyName: "events", Type: ColumnTypeInteger},
+	}, result.Data.Columns)
+	assert.Equal(2, len(result.Data.Rows))
+	assert.Equal("page_view", result.Data.Rows[0]["name"])
+	assert.Equal(1204.0, result.Data.Rows[0]["events"])
+}
+
+func TestExecuteQuery(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	body, err := ioutil.ReadFile("testdata/get-query-result.json")
+	if err != nil {
+		panic(err.Error())
+	}
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5/results",
+		func(request *http.Request) (*http.Response, error) {
+			payload := map[string]interface{}{}
+			assert.Nil(json.NewDecoder(request.Body).Decode(&payload))
+			assert.Equal(0.0, payload["max_age"])
+			return httpmock.NewStringResponse(200, `{"job": {"id": "abc-123", "status": 1}}`), nil
+		})
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/abc-123",
+		httpmock.ResponderFromMultipleResponses([]*http.Response{
+			httpmock.NewStringResponse(200, `{"job": {"id": "abc-123", "status": 1}}`),
+			httpmock.NewStringResponse(200, `{"job": {"id": "abc-123", "status": 2}}`),
+			httpmock.NewStringResponse(200, `{"job": {"id": "abc-123", "status": 3, "query_result_id": 42}}`),
+		}))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/query_results/42",
+		httpmock.NewStringResponder(200, string(body)))
+
+	result, err := c.ExecuteQuery(5, &ExecuteQueryOptions{PollInterval: time.Millisecond})
+	assert.Nil(err)
+	assert.Equal(42, result.ID)
+	assert.Equal(3, httpmock.GetCallCountInfo()["GET https://com.acme/api/jobs/abc-123"])
+}
+
+func TestExecuteQueryCached(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	body, err := ioutil.ReadFile("testdata/get-query-result.json")
+	if err != nil {
+		panic(err.Error())
+	}
+	httpmock.RegisterResponder("POST", "https://com.acme/api/query_results",
+		httpmock.NewStringResponder(200, string(body)))
+
+	result, err := c.ExecuteAdhocQuery(2, "SELECT 1;", &ExecuteQueryOptions{MaxAge: 3600})
+	assert.Nil(err)
+	assert.Equal(42, result.ID)
+	assert.Equal(1, httpmock.GetTotalCallCount())
+}
+
+func TestExecuteQueryFailure(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5/results",
+		httpmock.NewStringResponder(200, `{"job": {"id": "abc-123", "status": 1}}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/abc-123",
+		httpmock.NewStringResponder(200, `{"job": {"id": "abc-123", "status": 4, "error": "relation \"events\" does not exist"}}`))
+
+	result, err := c.ExecuteQuery(5, nil)
+	assert.Nil(result)
+
+	var jobError *JobError
+	assert.True(errors.As(err, &jobError))
+	assert.Equal(JobStatusFailure, jobError.Job.Status)
+	assert.Equal(`job abc-123 failure: relation "events" does not exist`, err.Error())
+}
+
+func TestExecuteQueryTimeout(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5/results",
+		httpmock.NewStringResponder(200, `{"job": {"id": "abc-123", "status": 1}}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/abc-123",
+		httpmock.NewStringResponder(200, `{"job": {"id": "abc-123", "status": 2}}`))
+
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/jobs/abc-123",
+		httpmock.NewStringResponder(200, `null`))
+
+	result, err := c.ExecuteQuery(5, &ExecuteQueryOptions{PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond})
+	assert.Nil(result)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal(1, httpmock.GetCallCountInfo()["DELETE https://com.acme/api/jobs/abc-123"])
+}
+
+func TestExecuteQueryCanceled(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	httpmock.RegisterResponder("POST", "https://com.acme/api/query_results",
+		httpmock.NewStringResponder(200, `{"job": {"id": "abc-123", "status": 1}}`))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/abc-123",
+		func(request *http.Request) (*http.Response, error) {
+			cancel()
+			return httpmock.NewStringResponse(200, `{"job": {"id": "abc-123", "status": 2}}`), nil
+		})
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/jobs/abc-123",
+		httpmock.NewStringResponder(200, `null`))
+
+	result, err := c.ExecuteAdhocQueryContext(ctx, 2, "SELECT pg_sleep(3600);", nil)
+	assert.Nil(result)
+	assert.ErrorIs(err, context.Canceled)
+	assert.Equal(1, httpmock.GetCallCountInfo()["DELETE https://com.acme/api/jobs/abc-123"])
+}
+
+func TestWaitForJobTimeout(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/jobs/abc-123",
+		httpmock.NewStringResponder(200, `{"job": {"id": "abc-123", "status": 2}}`))
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/jobs/abc-123",
+		httpmock.NewStringResponder(200, `null`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	job, err := c.WaitForJobContext(ctx, "abc-123", time.Millisecond)
+	assert.Equal(JobStatusStarted, job.Status)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal(1, httpmock.GetCallCountInfo()["DELETE https://com.acme/api/jobs/abc-123"])
+}
+
+func TestCancelJob(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("DELETE", "https://com.acme/api/jobs/abc-123",
+		httpmock.NewStringResponder(200, `null`))
+
+	err := c.CancelJob("abc-123")
+	assert.Nil(err)
+	assert.Equal(1, httpmock.GetTotalCallCount())
+}
+
+func TestDownloadQueryResult(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/5/results/42.csv",
+		httpmock.NewStringResponder(200, "name,events\npage_view,1204\nlink_click,87\n"))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/5/results/43.csv",
+		httpmock.NewStringResponder(404, `{"message": "Couldn't find resource."}`))
+
+	body, err := c.DownloadQueryResult(5, 42, ResultFormatCSV)
+	assert.Nil(err)
+	content, err := io.ReadAll(body)
+	assert.Nil(err)
+	assert.Nil(body.Close())
+	assert.Equal("name,events\npage_view,1204\nlink_click,87\n", string(content))
+
+	_, err = c.DownloadQueryResult(5, 43, ResultFormatCSV)
+	assert.True(IsNotFound(err))
+
+	_, err = c.DownloadQueryResult(5, 42, ResultFormat("pdf"))
+	assert.EqualError(err, "Unsupported result format: pdf")
+}
+
+func TestDownloadQueryResultToFile(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/5/results/42.tsv",
+		httpmock.NewStringResponder(200, "name\tevents\npage_view\t1204\n"))
+
+	filename := filepath.Join(t.TempDir(), "result.tsv")
+	err := c.DownloadQueryResultToFile(5, 42, ResultFormatTSV, filename)
+	assert.Nil(err)
+
+	content, err := ioutil.ReadFile(filename)
+	assert.Nil(err)
+	assert.Equal("name\tevents\npage_view\t1204\n", string(content))
+}
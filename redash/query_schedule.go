@@ -0,0 +1,219 @@
+package redash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule intervals, in seconds, as understood by Redash
+const (
+	ScheduleMinute = 60
+	ScheduleHour   = 60 * ScheduleMinute
+	ScheduleDay    = 24 * ScheduleHour
+	ScheduleWeek   = 7 * ScheduleDay
+)
+
+const (
+	scheduleTimeLayout  = "15:04"
+	scheduleUntilLayout = "2006-01-02"
+)
+
+// QuerySchedule defines when Redash refreshes a query. Interval is in seconds,
+// Time is the "HH:MM" UTC time of day for daily and weekly schedules,
+// DayOfWeek the English day name for weekly schedules and Until the
+// "YYYY-MM-DD" date after which the schedule stops. The zero value means the
+// query is not scheduled. Use NewSchedule to build one safely.
+type QuerySchedule struct {
+	Interval  int    `json:"interval"`
+	Time      string `json:"time,omitempty"`
+	DayOfWeek string `json:"day_of_week,omitempty"`
+	Until     string `json:"until,omitempty"`
+}
+
+// IsZero reports whether the schedule is empty, i.e. the query is only run on demand
+func (s QuerySchedule) IsZero() bool {
+	return s == QuerySchedule{}
+}
+
+// Validate checks the schedule is one Redash can run
+func (s QuerySchedule) Validate() error {
+	if s.IsZero() {
+		return nil
+	}
+	if s.Interval <= 0 {
+		return fmt.Errorf("schedule: interval must be positive, got %d", s.Interval)
+	}
+	if s.Interval%ScheduleMinute != 0 {
+		return fmt.Errorf("schedule: interval must be a whole number of minutes, got %ds", s.Interval)
+	}
+
+	if s.Time != "" {
+		if s.Interval%ScheduleDay != 0 {
+			return fmt.Errorf("schedule: a time of day needs an interval in whole days, got %ds", s.Interval)
+		}
+		if _, err := time.Parse(scheduleTimeLayout, s.Time); err != nil {
+			return fmt.Errorf("schedule: time %q is not in HH:MM format", s.Time)
+		}
+	}
+
+	if s.DayOfWeek != "" {
+		if s.Interval%ScheduleWeek != 0 {
+			return fmt.Errorf("schedule: a day of week needs an interval in whole weeks, got %ds", s.Interval)
+		}
+		if s.Time == "" {
+			return fmt.Errorf("schedule: a day of week needs a time of day")
+		}
+		if _, ok := parseWeekday(s.DayOfWeek); !ok {
+			return fmt.Errorf("schedule: %q is not a day of the week", s.DayOfWeek)
+		}
+	}
+
+	if s.Until != "" {
+		if _, err := time.Parse(scheduleUntilLayout, s.Until); err != nil {
+			return fmt.Errorf("schedule: until %q is not in YYYY-MM-DD format", s.Until)
+		}
+	}
+
+	return nil
+}
+
+// UntilDate returns the date the schedule stops, or the zero time if it never does
+func (s QuerySchedule) UntilDate() (time.Time, error) {
+	if s.Until == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(scheduleUntilLayout, s.Until)
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// ScheduleBuilder builds a validated QuerySchedule:
+//
+//	schedule, err := redash.NewSchedule().Daily().At(6, 30).Until(end).Build()
+type ScheduleBuilder struct {
+	schedule QuerySchedule
+	err      error
+}
+
+// NewSchedule starts building a query schedule
+func NewSchedule() *ScheduleBuilder {
+	return &ScheduleBuilder{}
+}
+
+func (b *ScheduleBuilder) every(n, unit int, name string) *ScheduleBuilder {
+	if n <= 0 && b.err == nil {
+		b.err = fmt.Errorf("schedule: number of %s must be positive, got %d", name, n)
+	}
+	b.schedule.Interval = n * unit
+	return b
+}
+
+// EveryMinutes refreshes the query every n minutes
+func (b *ScheduleBuilder) EveryMinutes(n int) *ScheduleBuilder {
+	return b.every(n, ScheduleMinute, "minutes")
+}
+
+// EveryHours refreshes the query every n hours
+func (b *ScheduleBuilder) EveryHours(n int) *ScheduleBuilder {
+	return b.every(n, ScheduleHour, "hours")
+}
+
+// EveryDays refreshes the query every n days, use At to set the time of day
+func (b *ScheduleBuilder) EveryDays(n int) *ScheduleBuilder {
+	return b.every(n, ScheduleDay, "days")
+}
+
+// Daily refreshes the query once a day, use At to set the time of day
+func (b *ScheduleBuilder) Daily() *ScheduleBuilder {
+	return b.EveryDays(1)
+}
+
+// Weekly refreshes the query once a week on the given day, use At to set the time of day
+func (b *ScheduleBuilder) Weekly(day time.Weekday) *ScheduleBuilder {
+	if (day < time.Sunday || day > time.Saturday) && b.err == nil {
+		b.err = fmt.Errorf("schedule: invalid day of week %d", day)
+	}
+	b.schedule.Interval = ScheduleWeek
+	b.schedule.DayOfWeek = day.String()
+	return b
+}
+
+// At sets the UTC time of day for daily and weekly schedules
+func (b *ScheduleBuilder) At(hour, minute int) *ScheduleBuilder {
+	if (hour < 0 || hour > 23 || minute < 0 || minute > 59) && b.err == nil {
+		b.err = fmt.Errorf("schedule: invalid time of day %02d:%02d", hour, minute)
+	}
+	b.schedule.Time = fmt.Sprintf("%02d:%02d", hour, minute)
+	return b
+}
+
+// Until stops the schedule after the given date. Only the date part is kept.
+func (b *ScheduleBuilder) Until(date time.Time) *ScheduleBuilder {
+	b.schedule.Until = date.Format(scheduleUntilLayout)
+	return b
+}
+
+// Build validates and returns the schedule
+func (b *ScheduleBuilder) Build() (*QuerySchedule, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.schedule.Interval == 0 {
+		return nil, fmt.Errorf("schedule: no interval set")
+	}
+	if b.schedule.Interval%ScheduleDay == 0 && b.schedule.Time == "" {
+		return nil, fmt.Errorf("schedule: daily and weekly schedules need a time of day")
+	}
+	if err := b.schedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	schedule := b.schedule
+	return &schedule, nil
+}
+
+// RefreshQuery asks Redash to refresh a saved query, bypassing any cached
+// result. Parameter values are sent as strings, as in a Redash URL. The
+// returned job can be passed to WaitForJob.
+func (c *Client) RefreshQuery(id int, parameters map[string]string) (*Job, error) {
+	return c.RefreshQueryContext(context.Background(), id, parameters)
+}
+
+// RefreshQueryContext is like RefreshQuery but uses ctx for the underlying requests.
+func (c *Client) RefreshQueryContext(ctx context.Context, id int, parameters map[string]string) (*Job, error) {
+	path := "/api/queries/" + strconv.Itoa(id) + "/refresh"
+
+	queryParams := url.Values{}
+	for name, value := range parameters {
+		queryParams.Set("p_"+name, value)
+	}
+
+	response, err := c.post(ctx, path, "", queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	execution := new(QueryExecution)
+	err = json.NewDecoder(response.Body).Decode(execution)
+	if err != nil {
+		return nil, err
+	}
+	if execution.Job == nil {
+		return nil, fmt.Errorf("refresh of query %d returned no job", id)
+	}
+
+	return execution.Job, nil
+}
@@ -0,0 +1,94 @@
+package redash
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	schedule, err := NewSchedule().EveryMinutes(15).Build()
+	assert.Nil(err)
+	assert.Equal(QuerySchedule{Interval: 900}, *schedule)
+
+	until := time.Date(2024, time.March, 31, 12, 0, 0, 0, time.UTC)
+	schedule, err = NewSchedule().Daily().At(6, 30).Until(until).Build()
+	assert.Nil(err)
+	assert.Equal(QuerySchedule{Interval: 86400, Time: "06:30", Until: "2024-03-31"}, *schedule)
+
+	schedule, err = NewSchedule().Weekly(time.Monday).At(9, 0).Build()
+	assert.Nil(err)
+	assert.Equal(QuerySchedule{Interval: 604800, Time: "09:00", DayOfWeek: "Monday"}, *schedule)
+
+	_, err = NewSchedule().EveryMinutes(0).Build()
+	assert.EqualError(err, "schedule: number of minutes must be positive, got 0")
+
+	_, err = NewSchedule().Daily().At(24, 0).Build()
+	assert.EqualError(err, "schedule: invalid time of day 24:00")
+
+	_, err = NewSchedule().Daily().Build()
+	assert.EqualError(err, "schedule: daily and weekly schedules need a time of day")
+
+	_, err = NewSchedule().EveryHours(2).At(3, 0).Build()
+	assert.EqualError(err, "schedule: a time of day needs an interval in whole days, got 7200s")
+
+	_, err = NewSchedule().Build()
+	assert.EqualError(err, "schedule: no interval set")
+}
+
+func TestQueryScheduleJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	schedule := QuerySchedule{}
+	assert.Nil(json.Unmarshal([]byte(`{"interval": 604800, "time": "09:00", "day_of_week": "Monday", "until": "2024-03-31"}`), &schedule))
+	assert.Nil(schedule.Validate())
+
+	until, err := schedule.UntilDate()
+	assert.Nil(err)
+	assert.Equal(time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), until)
+
+	built, err := NewSchedule().Weekly(time.Monday).At(9, 0).Until(until).Build()
+	assert.Nil(err)
+	assert.Equal(schedule, *built)
+
+	payload, err := json.Marshal(built)
+	assert.Nil(err)
+	assert.JSONEq(`{"interval": 604800, "time": "09:00", "day_of_week": "Monday", "until": "2024-03-31"}`, string(payload))
+
+	query := Query{}
+	assert.Nil(json.Unmarshal([]byte(`{"id": 1, "schedule": null}`), &query))
+	assert.True(query.Schedule.IsZero())
+	assert.Nil(query.Schedule.Validate())
+
+	assert.EqualError(QuerySchedule{Interval: 86400, DayOfWeek: "Monday", Time: "10:00"}.Validate(),
+		"schedule: a day of week needs an interval in whole weeks, got 86400s")
+	assert.EqualError(QuerySchedule{Interval: 604800, DayOfWeek: "Funday", Time: "10:00"}.Validate(),
+		`schedule: "Funday" is not a day of the week`)
+	assert.EqualError(QuerySchedule{Interval: 86400, Time: "6pm"}.Validate(),
+		`schedule: time "6pm" is not in HH:MM format`)
+	assert.EqualError(QuerySchedule{Interval: 300, Until: "31/03/2024"}.Validate(),
+		`schedule: until "31/03/2024" is not in YYYY-MM-DD format`)
+	assert.EqualError(QuerySchedule{Interval: 90}.Validate(),
+		"schedule: interval must be a whole number of minutes, got 90s")
+}
+
+func TestRefreshQuery(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponderWithQuery("POST", "https://com.acme/api/queries/5/refresh", "p_country=NL",
+		httpmock.NewStringResponder(200, `{"job": {"id": "abc-123", "status": 1, "error": "", "query_result_id": null, "updated_at": 0}}`))
+
+	job, err := c.RefreshQuery(5, map[string]string{"country": "NL"})
+	assert.Nil(err)
+	assert.Equal("abc-123", job.ID)
+	assert.Equal(JobStatusPending, job.Status)
+}
@@ -0,0 +1,221 @@
+package redash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryVersion models an entry of a query's change history, as returned by
+// Redash's /api/queries/{id}/version endpoint
+type QueryVersion struct {
+	ID            int                           `json:"id"`
+	ObjectID      int                           `json:"object_id"`
+	ObjectType    string                        `json:"object_type"`
+	ObjectVersion int                           `json:"object_version"`
+	Change        map[string]QueryVersionChange `json:"change"`
+	User          User                          `json:"user"`
+	CreatedAt     time.Time                     `json:"created_at"`
+}
+
+// QueryVersionChange holds the previous and current value of a changed field
+type QueryVersionChange struct {
+	Previous interface{} `json:"previous"`
+	Current  interface{} `json:"current"`
+}
+
+// VersionConflictError is returned by UpdateQuery when the Version sent is
+// stale because the query was modified in the meantime
+type VersionConflictError struct {
+	QueryID  int
+	Version  int
+	APIError *APIError
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("query %d was modified since version %d: %s", e.QueryID, e.Version, e.APIError)
+}
+
+// Unwrap returns the underlying 409 *APIError, so IsConflict also matches
+func (e *VersionConflictError) Unwrap() error {
+	return e.APIError
+}
+
+// GetQueryVersions returns the change history of a query, oldest first
+func (c *Client) GetQueryVersions(id int) ([]QueryVersion, error) {
+	return c.GetQueryVersionsContext(context.Background(), id)
+}
+
+// GetQueryVersionsContext is like GetQueryVersions but uses ctx for the underlying requests.
+func (c *Client) GetQueryVersionsContext(ctx context.Context, id int) ([]QueryVersion, error) {
+	path := "/api/queries/" + strconv.Itoa(id) + "/version"
+
+	response, err := c.get(ctx, path, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	versions := []QueryVersion{}
+	err = json.NewDecoder(response.Body).Decode(&versions)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].ObjectVersion < versions[j].ObjectVersion
+	})
+
+	return versions, nil
+}
+
+// QueryTextAtVersion returns the SQL text a query had at the given version,
+// replaying its change history
+func QueryTextAtVersion(versions []QueryVersion, version int) (string, error) {
+	text, found := "", false
+	for _, v := range versions {
+		if v.ObjectVersion > version {
+			break
+		}
+		change, changed := v.Change["query"]
+		if !changed {
+			continue
+		}
+		current, ok := change.Current.(string)
+		if !ok {
+			return "", fmt.Errorf("Unexpected query text in version %d", v.ObjectVersion)
+		}
+		text, found = current, true
+	}
+
+	if !found {
+		return "", newNotFoundError("no query text recorded up to version %d", version)
+	}
+	return text, nil
+}
+
+// GetQueryTextAtVersion returns the SQL text of a query at a given version
+func (c *Client) GetQueryTextAtVersion(id, version int) (string, error) {
+	return c.GetQueryTextAtVersionContext(context.Background(), id, version)
+}
+
+// GetQueryTextAtVersionContext is like GetQueryTextAtVersion but uses ctx for the underlying requests.
+func (c *Client) GetQueryTextAtVersionContext(ctx context.Context, id, version int) (string, error) {
+	versions, err := c.GetQueryVersionsContext(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return QueryTextAtVersion(versions, version)
+}
+
+// RevertQuery restores the SQL text a query had at the given version. The
+// revert is saved as a new version, guarded by the current version number.
+func (c *Client) RevertQuery(id, version int) (*Query, error) {
+	return c.RevertQueryContext(context.Background(), id, version)
+}
+
+// RevertQueryContext is like RevertQuery but uses ctx for the underlying requests.
+func (c *Client) RevertQueryContext(ctx context.Context, id, version int) (*Query, error) {
+	text, err := c.GetQueryTextAtVersionContext(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := c.GetQueryContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule *QuerySchedule
+	if !query.Schedule.IsZero() {
+		schedule = &query.Schedule
+	}
+
+	return c.UpdateQueryContext(ctx, id, &QueryUpdatePayload{
+		Name:         query.Name,
+		Description:  query.Description,
+		Query:        text,
+		DataSourceID: query.DataSourceID,
+		IsDraft:      query.IsDraft,
+		Schedule:     schedule,
+		Version:      query.Version,
+		Tags:         query.Tags,
+	})
+}
+
+// DiffQueryVersions returns a line based diff of the SQL text of a query
+// between two versions
+func (c *Client) DiffQueryVersions(id, from, to int) (string, error) {
+	return c.DiffQueryVersionsContext(context.Background(), id, from, to)
+}
+
+// DiffQueryVersionsContext is like DiffQueryVersions but uses ctx for the underlying requests.
+func (c *Client) DiffQueryVersionsContext(ctx context.Context, id, from, to int) (string, error) {
+	versions, err := c.GetQueryVersionsContext(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	fromText, err := QueryTextAtVersion(versions, from)
+	if err != nil {
+		return "", err
+	}
+	toText, err := QueryTextAtVersion(versions, to)
+	if err != nil {
+		return "", err
+	}
+
+	return DiffText("version "+strconv.Itoa(from), "version "+strconv.Itoa(to), fromText, toText), nil
+}
+
+// DiffText returns a diff of two texts: unchanged lines are prefixed with a
+// space, removed lines with "-" and added lines with "+"
+func DiffText(fromName, toName, from, to string) string {
+	a := strings.Split(from, "\n")
+	b := strings.Split(to, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff strings.Builder
+	diff.WriteString("--- " + fromName + "\n")
+	diff.WriteString("+++ " + toName + "\n")
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff.WriteString(" " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff.WriteString("-" + a[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+" + b[j] + "\n")
+			j++
+		}
+	}
+
+	return diff.String()
+}
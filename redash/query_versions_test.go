@@ -0,0 +1,158 @@
+package redash
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const queryVersionsResponse = `[
+  {"id": 12, "object_id": 5, "object_type": "queries", "object_version": 3,
+   "change": {"query": {"previous": "SELECT id, name\nFROM users", "current": "SELECT id, name\nFROM users\nWHERE active"}},
+   "user": {"id": 2, "name": "Developer"}, "created_at": "2023-01-03T02:57:24.435Z"},
+  {"id": 10, "object_id": 5, "object_type": "queries", "object_version": 1,
+   "change": {"query": {"previous": null, "current": "SELECT id\nFROM users"}, "name": {"previous": null, "current": "Users"}},
+   "user": {"id": 1, "name": "Admin"}, "created_at": "2023-01-01T10:00:00.000Z"},
+  {"id": 11, "object_id": 5, "object_type": "queries", "object_version": 2,
+   "change": {"query": {"previous": "SELECT id\nFROM users", "current": "SELECT id, name\nFROM users"}},
+   "user": {"id": 1, "name": "Admin"}, "created_at": "2023-01-02T10:00:00.000Z"},
+  {"id": 13, "object_id": 5, "object_type": "queries", "object_version": 4,
+   "change": {"description": {"previous": "", "current": "Active users"}},
+   "user": {"id": 2, "name": "Developer"}, "created_at": "2023-01-04T10:00:00.000Z"}
+]`
+
+func TestGetQueryVersions(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/5/version",
+		httpmock.NewStringResponder(200, queryVersionsResponse))
+
+	versions, err := c.GetQueryVersions(5)
+	assert.Nil(err)
+	assert.Equal(4, len(versions))
+	assert.Equal(1, versions[0].ObjectVersion)
+	assert.Equal("Admin", versions[0].User.Name)
+	assert.Equal("Users", versions[0].Change["name"].Current)
+
+	text, err := c.GetQueryTextAtVersion(5, 2)
+	assert.Nil(err)
+	assert.Equal("SELECT id, name\nFROM users", text)
+
+	// versions that did not touch the SQL keep the previous text
+	text, err = QueryTextAtVersion(versions, 4)
+	assert.Nil(err)
+	assert.Equal("SELECT id, name\nFROM users\nWHERE active", text)
+
+	_, err = QueryTextAtVersion(versions, 0)
+	assert.True(IsNotFound(err))
+}
+
+func TestDiffQueryVersions(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/5/version",
+		httpmock.NewStringResponder(200, queryVersionsResponse))
+
+	diff, err := c.DiffQueryVersions(5, 1, 3)
+	assert.Nil(err)
+	assert.Equal(`--- version 1
++++ version 3
+-SELECT id
++SELECT id, name
+ FROM users
++WHERE active
+`, diff)
+}
+
+func TestRevertQuery(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/5/version",
+		httpmock.NewStringResponder(200, queryVersionsResponse))
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/5",
+		httpmock.NewStringResponder(200, `{"id": 5, "name": "Users", "query": "SELECT id, name\nFROM users\nWHERE active", "data_source_id": 1, "version": 4, "schedule": null}`))
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5",
+		func(request *http.Request) (*http.Response, error) {
+			payload := map[string]interface{}{}
+			assert.Nil(json.NewDecoder(request.Body).Decode(&payload))
+			assert.Equal("SELECT id\nFROM users", payload["query"])
+			assert.Equal(4.0, payload["version"])
+			assert.Nil(payload["schedule"])
+			return httpmock.NewStringResponse(200, `{"id": 5, "query": "SELECT id\nFROM users", "version": 5}`), nil
+		})
+
+	query, err := c.RevertQuery(5, 1)
+	assert.Nil(err)
+	assert.Equal(5, query.Version)
+	assert.Equal("SELECT id\nFROM users", query.Query)
+}
+
+func TestUpdateQueryVersionConflict(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5",
+		httpmock.NewStringResponder(409, `{"message": "Overwrite of newer version"}`))
+
+	_, err := c.UpdateQuery(5, &QueryUpdatePayload{Query: "SELECT 1;", Version: 2})
+
+	var conflict *VersionConflictError
+	assert.True(errors.As(err, &conflict))
+	assert.Equal(5, conflict.QueryID)
+	assert.Equal(2, conflict.Version)
+	assert.Equal("Overwrite of newer version", conflict.APIError.Message)
+	assert.True(IsConflict(err))
+
+	// a nil payload carries no version to conflict with
+	_, err = c.UpdateQuery(5, nil)
+	assert.True(IsConflict(err))
+	assert.False(errors.As(err, &conflict))
+}
+
+func TestUpdateQueryVersionNotReplayed(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{
+		RedashURI: "https://com.acme/",
+		APIKey:    "ApIkEyApIkEyApIkEyApIkEyApIkEy",
+		Retry:     &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	})
+
+	// the first attempt was saved before the gateway timed out, so a replay
+	// would conflict with the version it created
+	httpmock.RegisterResponder("POST", "https://com.acme/api/queries/5",
+		httpmock.ResponderFromMultipleResponses([]*http.Response{
+			httpmock.NewStringResponse(504, "gateway timeout"),
+			httpmock.NewStringResponse(409, `{"message": "Overwrite of newer version"}`),
+		}))
+
+	_, err := c.UpdateQuery(5, &QueryUpdatePayload{Query: "SELECT 1;", Version: 2})
+
+	var apiError *APIError
+	assert.True(errors.As(err, &apiError))
+	assert.Equal(504, apiError.StatusCode)
+	assert.False(IsConflict(err))
+	assert.Equal(1, httpmock.GetCallCountInfo()["POST https://com.acme/api/queries/5"])
+}
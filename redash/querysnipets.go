@@ -1,6 +1,7 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/url"
@@ -32,8 +33,13 @@ type UpdateQuerySnippetPayload struct {
 }
 
 func (c *Client) GetQuerySnippets() (*[]QuerySnippet, error) {
+	return c.GetQuerySnippetsContext(context.Background())
+}
+
+// GetQuerySnippetsContext is like GetQuerySnippets but uses ctx for the underlying requests.
+func (c *Client) GetQuerySnippetsContext(ctx context.Context) (*[]QuerySnippet, error) {
 	path := "/api/query_snippets"
-	res, err := c.get(path, url.Values{})
+	res, err := c.get(ctx, path, url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -54,9 +60,14 @@ func (c *Client) GetQuerySnippets() (*[]QuerySnippet, error) {
 }
 
 func (c *Client) GetQuerySnippet(id int) (*QuerySnippet, error) {
+	return c.GetQuerySnippetContext(context.Background(), id)
+}
+
+// GetQuerySnippetContext is like GetQuerySnippet but uses ctx for the underlying requests.
+func (c *Client) GetQuerySnippetContext(ctx context.Context, id int) (*QuerySnippet, error) {
 	path := "/api/query_snippets/" + strconv.Itoa(id)
 
-	res, err := c.get(path, url.Values{})
+	res, err := c.get(ctx, path, url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -77,13 +88,18 @@ func (c *Client) GetQuerySnippet(id int) (*QuerySnippet, error) {
 }
 
 func (c *Client) CreateQuerySnippet(createQuerySnippetPayload CreateQuerySnippetPayload) (*QuerySnippet, error) {
+	return c.CreateQuerySnippetContext(context.Background(), createQuerySnippetPayload)
+}
+
+// CreateQuerySnippetContext is like CreateQuerySnippet but uses ctx for the underlying requests.
+func (c *Client) CreateQuerySnippetContext(ctx context.Context, createQuerySnippetPayload CreateQuerySnippetPayload) (*QuerySnippet, error) {
 	path := "/api/query_snippets"
 	payload, err := json.Marshal(createQuerySnippetPayload)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := c.post(path, string(payload), url.Values{})
+	res, err := c.post(ctx, path, string(payload), url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -103,13 +119,18 @@ func (c *Client) CreateQuerySnippet(createQuerySnippetPayload CreateQuerySnippet
 }
 
 func (c *Client) UpdateQuerySnippet(id int, updateQuerySnippetPalyload UpdateQuerySnippetPayload) (*QuerySnippet, error) {
+	return c.UpdateQuerySnippetContext(context.Background(), id, updateQuerySnippetPalyload)
+}
+
+// UpdateQuerySnippetContext is like UpdateQuerySnippet but uses ctx for the underlying requests.
+func (c *Client) UpdateQuerySnippetContext(ctx context.Context, id int, updateQuerySnippetPalyload UpdateQuerySnippetPayload) (*QuerySnippet, error) {
 	path := "/api/query_snippets/" + strconv.Itoa(id)
 	payload, err := json.Marshal(updateQuerySnippetPalyload)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := c.post(path, string(payload), url.Values{})
+	res, err := c.post(ctx, path, string(payload), url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -130,11 +151,17 @@ func (c *Client) UpdateQuerySnippet(id int, updateQuerySnippetPalyload UpdateQue
 }
 
 func (c *Client) DeleteQuerySnippet(id int) error {
+	return c.DeleteQuerySnippetContext(context.Background(), id)
+}
+
+// DeleteQuerySnippetContext is like DeleteQuerySnippet but uses ctx for the underlying requests.
+func (c *Client) DeleteQuerySnippetContext(ctx context.Context, id int) error {
 	path := "/api/query_snippets/" + strconv.Itoa(id)
-	_, err := c.delete(path, url.Values{})
+	response, err := c.delete(ctx, path, url.Values{})
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 
 	return nil
 }
@@ -0,0 +1,221 @@
+package redash
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Defaults applied to zero-valued RetryPolicy fields
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryMinBackoff  = 500 * time.Millisecond
+	DefaultRetryMaxBackoff  = 30 * time.Second
+)
+
+// DefaultRetryStatusCodes are the transient statuses retried when
+// RetryPolicy.StatusCodes is empty
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures how failed requests are retried. Requests are only
+// retried when it is safe to do so: GET, PUT and DELETE always, POST only for
+// endpoints that overwrite an existing resource (see isIdempotent) and never
+// for updates checked against a query version. Any request answered with 429
+// is retried since Redash rejected it before processing.
+//
+// A replayed DELETE whose earlier attempt was applied, but whose response was
+// lost, is answered with 404 and returned as an *APIError. When retries are
+// enabled, callers deleting a resource, cancelling a job or removing a
+// favorite may treat IsNotFound as success.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, doubled on every attempt
+	MinBackoff time.Duration
+	// MaxBackoff caps the exponential delay and any Retry-After header
+	MaxBackoff time.Duration
+	// StatusCodes lists the response statuses that trigger a retry
+	StatusCodes []int
+}
+
+// NewRetryPolicy returns a RetryPolicy populated with the defaults
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DefaultRetryMaxAttempts,
+		MinBackoff:  DefaultRetryMinBackoff,
+		MaxBackoff:  DefaultRetryMaxBackoff,
+		StatusCodes: DefaultRetryStatusCodes,
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil {
+		return 1
+	}
+	if p.MaxAttempts <= 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) retryableStatus(statusCode int) bool {
+	statusCodes := p.StatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = DefaultRetryStatusCodes
+	}
+	for _, code := range statusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldRetry decides whether the outcome of an attempt warrants another one
+func (p *RetryPolicy) shouldRetry(ctx context.Context, attempt int, method, path string, response *http.Response, err error) bool {
+	if attempt >= p.maxAttempts() || ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		// the request may have reached Redash, so only replay it when safe
+		return isReplayable(ctx, method, path)
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return p.retryableStatus(response.StatusCode)
+	}
+
+	return p.retryableStatus(response.StatusCode) && isReplayable(ctx, method, path)
+}
+
+// backoff returns the delay before the next attempt. Retry-After is honoured
+// when present, otherwise an exponential delay with jitter is used.
+func (p *RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	minBackoff, maxBackoff := p.MinBackoff, p.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultRetryMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+
+	if response != nil {
+		if wait, ok := parseRetryAfter(response.Header.Get("Retry-After"), time.Now()); ok {
+			if wait > maxBackoff {
+				return maxBackoff
+			}
+			return wait
+		}
+	}
+
+	wait := minBackoff
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+
+	// equal jitter: wait somewhere between half and the full delay
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter understands both forms of the header: delay-seconds and an
+// HTTP-date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// idempotentPostPaths are Redash endpoints where POST overwrites the state of
+// an existing resource, so replaying the request has no additional effect
+var idempotentPostPaths = []*regexp.Regexp{
+	regexp.MustCompile(`^/api/(queries|dashboards|widgets|visualizations|alerts|users|groups|data_sources|destinations|query_snippets)/\d+$`),
+	regexp.MustCompile(`^/api/users/\d+/disable$`),
+	regexp.MustCompile(`^/api/(queries|dashboards)/[^/]+/favorite$`),
+	regexp.MustCompile(`^/api/data_sources/\d+/(pause|test)$`),
+	regexp.MustCompile(`^/api/alerts/\d+/mute$`),
+}
+
+// isIdempotent reports whether a request can be safely sent more than once
+func isIdempotent(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		for _, pattern := range idempotentPostPaths {
+			if pattern.MatchString(path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// noReplayKey marks the context of a request that must not be replayed
+type noReplayKey struct{}
+
+// withoutReplay returns a context whose requests are only retried when
+// rate limited. Version-checked updates use it, a replay of one that already
+// succeeded would be rejected as a conflict with the version it created.
+func withoutReplay(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noReplayKey{}, true)
+}
+
+// isReplayable reports whether a request that may have reached Redash can be
+// sent again
+func isReplayable(ctx context.Context, method, path string) bool {
+	if noReplay, _ := ctx.Value(noReplayKey{}).(bool); noReplay {
+		return false
+	}
+	return isIdempotent(method, path)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
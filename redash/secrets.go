@@ -0,0 +1,217 @@
+package redash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSecretNotFound is returned by the built-in SecretProviders when a secret doesn't exist
+var ErrSecretNotFound = errors.New("redash: secret not found")
+
+// SecretRef stands in for a secret option value of a data source or
+// destination. When creating or updating, it's replaced by the value
+// Config.SecretProvider returns for the name, so the secret itself never has
+// to be part of the payload built by the caller:
+//
+//	options["password"] = redash.SecretRef("WAREHOUSE_PASSWORD")
+type SecretRef string
+
+// SecretProvider looks up secret values by name
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// SecretProviderFunc is an adapter to use a function as a SecretProvider
+type SecretProviderFunc func(ctx context.Context, name string) (string, error)
+
+// Secret calls f(ctx, name)
+func (f SecretProviderFunc) Secret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// EnvSecretProvider reads secrets from the environment variable named prefix + name
+func EnvSecretProvider(prefix string) SecretProvider {
+	return SecretProviderFunc(func(ctx context.Context, name string) (string, error) {
+		value, ok := os.LookupEnv(prefix + name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s: %w", prefix+name, ErrSecretNotFound)
+		}
+		return value, nil
+	})
+}
+
+// FileSecretProvider reads secrets from the file named after them in dir, as
+// mounted by Docker and Kubernetes. A trailing newline is removed.
+func FileSecretProvider(dir string) SecretProvider {
+	return SecretProviderFunc(func(ctx context.Context, name string) (string, error) {
+		if name == "" || filepath.Base(name) != name || name == "." || name == ".." {
+			return "", fmt.Errorf("invalid secret name %q", name)
+		}
+
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("file %s: %w", path, ErrSecretNotFound)
+		}
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	})
+}
+
+// resolveSecrets replaces SecretRefs in options with the values from the
+// configured SecretProvider. The options are copied first, so the caller's
+// map never holds the secrets.
+func (c *Client) resolveSecrets(ctx context.Context, options map[string]interface{}) (map[string]interface{}, error) {
+	hasRefs := false
+	for _, value := range options {
+		if _, ok := value.(SecretRef); ok {
+			hasRefs = true
+			break
+		}
+	}
+	if !hasRefs {
+		return options, nil
+	}
+
+	resolved := make(map[string]interface{}, len(options))
+	for name, value := range options {
+		ref, ok := value.(SecretRef)
+		if !ok {
+			resolved[name] = value
+			continue
+		}
+
+		if c.Config.SecretProvider == nil {
+			return nil, fmt.Errorf("option %s refers to secret %q but no SecretProvider is configured", name, string(ref))
+		}
+		secret, err := c.Config.SecretProvider.Secret(ctx, string(ref))
+		if err != nil {
+			return nil, fmt.Errorf("option %s: %w", name, err)
+		}
+		resolved[name] = secret
+	}
+
+	return resolved, nil
+}
+
+// checkMaskedSecrets rejects options holding the SecretMask Redash returns in
+// place of secrets, which would otherwise be stored as the secret itself
+func checkMaskedSecrets(options map[string]interface{}) error {
+	for name, value := range options {
+		if value == SecretMask {
+			return fmt.Errorf("option %s holds the masked value Redash returns for secrets, the actual secret is needed", name)
+		}
+	}
+	return nil
+}
+
+// secretNameHints are parts of option names that hold credentials in Redash
+// query runners and destinations, used when the schema isn't at hand
+var secretNameHints = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "private_key", "credential"}
+
+func looksSecret(name string) bool {
+	name = strings.ToLower(name)
+	for _, hint := range secretNameHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactOptions returns a copy of options fit for logging, with the values of
+// secret options replaced by SecretMask. secrets names the secret options,
+// usually ConfigurationSchema.Secret. Without them, options whose name
+// suggests a credential, such as password, token or api_key, are redacted.
+func RedactOptions(options map[string]interface{}, secrets ...string) map[string]interface{} {
+	if len(secrets) == 0 {
+		return redactOptions(options, looksSecret)
+	}
+	return redactOptions(options, func(name string) bool {
+		return containsString(secrets, name)
+	})
+}
+
+// Redact returns a copy of options with the values of the schema's secret options masked, see RedactOptions
+func (s *ConfigurationSchema) Redact(options map[string]interface{}) map[string]interface{} {
+	return redactOptions(options, s.IsSecret)
+}
+
+// redactTypedOptions masks the given secrets of a known type along with the
+// options whose name suggests a credential, for String methods which don't
+// have the type's schema at hand
+func redactTypedOptions(options map[string]interface{}, secrets []string) map[string]interface{} {
+	return redactOptions(options, func(name string) bool {
+		return containsString(secrets, name) || looksSecret(name)
+	})
+}
+
+func redactOptions(options map[string]interface{}, isSecret func(name string) bool) map[string]interface{} {
+	if options == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(options))
+	for name, value := range options {
+		redacted[name] = value
+		if _, isRef := value.(SecretRef); value == nil || isRef {
+			continue
+		}
+		if isSecret(name) {
+			redacted[name] = SecretMask
+		}
+	}
+
+	return redacted
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeOptions overlays changes on the current options of a data source or
+// destination. Masked secrets in changes keep the current value and a nil
+// value removes the option.
+func mergeOptions(current, changes map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(current)+len(changes))
+	for name, value := range current {
+		merged[name] = value
+	}
+	for name, value := range changes {
+		switch {
+		case value == nil:
+			delete(merged, name)
+		case value == SecretMask:
+			continue
+		default:
+			merged[name] = value
+		}
+	}
+	return merged
+}
+
+// optionsEqual compares two option maps, regardless of the numeric Go types
+// of their values
+func optionsEqual(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		other, exists := b[name]
+		if !exists || !optionValuesEqual(value, other) {
+			return false
+		}
+	}
+	return true
+}
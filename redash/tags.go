@@ -0,0 +1,78 @@
+package redash
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+)
+
+// Tag is a tag in use by queries or dashboards, along with the number of
+// objects carrying it
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// TagList models the response of the tag listing endpoints
+type TagList struct {
+	Tags []Tag `json:"tags"`
+}
+
+// Names returns the tag names in alphabetical order
+func (l *TagList) Names() []string {
+	names := make([]string, 0, len(l.Tags))
+	for _, tag := range l.Tags {
+		names = append(names, tag.Name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Count returns how many objects carry the named tag, or 0 if it's not in use
+func (l *TagList) Count(name string) int {
+	for _, tag := range l.Tags {
+		if tag.Name == name {
+			return tag.Count
+		}
+	}
+
+	return 0
+}
+
+// GetQueryTags returns the tags in use by queries
+func (c *Client) GetQueryTags() (*TagList, error) {
+	return c.GetQueryTagsContext(context.Background())
+}
+
+// GetQueryTagsContext is like GetQueryTags but uses ctx for the underlying requests.
+func (c *Client) GetQueryTagsContext(ctx context.Context) (*TagList, error) {
+	return c.getTags(ctx, "/api/queries/tags")
+}
+
+// GetDashboardTags returns the tags in use by dashboards
+func (c *Client) GetDashboardTags() (*TagList, error) {
+	return c.GetDashboardTagsContext(context.Background())
+}
+
+// GetDashboardTagsContext is like GetDashboardTags but uses ctx for the underlying requests.
+func (c *Client) GetDashboardTagsContext(ctx context.Context) (*TagList, error) {
+	return c.getTags(ctx, "/api/dashboards/tags")
+}
+
+func (c *Client) getTags(ctx context.Context, path string) (*TagList, error) {
+	response, err := c.get(ctx, path, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	tags := new(TagList)
+	err = json.NewDecoder(response.Body).Decode(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
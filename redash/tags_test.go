@@ -0,0 +1,41 @@
+package redash
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetQueryTags(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/queries/tags",
+		httpmock.NewStringResponder(200, `{"tags": [{"name": "sales", "count": 4}, {"name": "finance", "count": 2}]}`))
+
+	tags, err := c.GetQueryTags()
+	assert.Nil(err)
+	assert.Equal(2, len(tags.Tags))
+	assert.Equal([]string{"finance", "sales"}, tags.Names())
+	assert.Equal(4, tags.Count("sales"))
+	assert.Equal(0, tags.Count("marketing"))
+}
+
+func TestGetDashboardTags(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponder("GET", "https://com.acme/api/dashboards/tags",
+		httpmock.NewStringResponder(200, `{"tags": [{"name": "slo", "count": 1}]}`))
+
+	tags, err := c.GetDashboardTags()
+	assert.Nil(err)
+	assert.Equal([]Tag{{Name: "slo", Count: 1}}, tags.Tags)
+}
@@ -0,0 +1,71 @@
+package redash
+
+import (
+	"net/http"
+)
+
+// Middleware wraps an http.RoundTripper with additional behaviour such as
+// injecting headers, logging or collecting metrics
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts an ordinary function to the http.RoundTripper interface
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(request)
+func (f RoundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// Chain composes several middlewares into one. The first middleware is the
+// outermost, so it sees the request first and the response last.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if middlewares[i] != nil {
+				next = middlewares[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// WithHeader returns a Middleware that sets a header on every outgoing request
+func WithHeader(key, value string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			request = request.Clone(request.Context())
+			request.Header.Set(key, value)
+			return next.RoundTrip(request)
+		})
+	}
+}
+
+// defaultTransport defers to http.DefaultTransport at request time, so a client
+// built without an explicit transport behaves exactly like http.DefaultClient
+var defaultTransport = RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(request)
+})
+
+// newHTTPClient builds the *http.Client used by doRequest from the Config. The
+// user supplied client is copied so that wrapping its transport with the
+// middleware chain never mutates the caller's value.
+func newHTTPClient(config *Config) *http.Client {
+	if config.HTTPClient == nil && len(config.Middleware) == 0 {
+		return http.DefaultClient
+	}
+
+	httpClient := &http.Client{}
+	if config.HTTPClient != nil {
+		*httpClient = *config.HTTPClient
+	}
+
+	if len(config.Middleware) > 0 {
+		var transport http.RoundTripper = defaultTransport
+		if httpClient.Transport != nil {
+			transport = httpClient.Transport
+		}
+		httpClient.Transport = Chain(config.Middleware...)(transport)
+	}
+
+	return httpClient
+}
@@ -14,8 +14,8 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/url"
 	"strconv"
@@ -24,27 +24,79 @@ import (
 
 // UserList struct
 type UserList struct {
-	Count    int `json:"count"`
-	Page     int `json:"page"`
-	PageSize int `json:"page_size"`
-	Results  []struct {
-		AuthType            string    `json:"auth_type,omitempty"`
-		IsDisabled          bool      `json:"is_disabled,omitempty"`
-		UpdatedAt           time.Time `json:"updated_at,omitempty"`
-		ProfileImageURL     string    `json:"profile_image_url,omitempty"`
-		IsInvitationPending bool      `json:"is_invitation_pending,omitempty"`
-		Groups              []struct {
-			ID   int    `json:"id,omitempty"`
-			Name string `json:"name,omitempty"`
-		} `json:"groups,omitempty"`
-		ID              int         `json:"id,omitempty"`
-		Name            string      `json:"name,omitempty"`
-		CreatedAt       time.Time   `json:"created_at,omitempty"`
-		DisabledAt      interface{} `json:"disabled_at,omitempty"`
-		IsEmailVerified bool        `json:"is_email_verified,omitempty"`
-		ActiveAt        time.Time   `json:"active_at,omitempty"`
-		Email           string      `json:"email,omitempty"`
-	} `json:"results,omitempty"`
+	Count    int           `json:"count"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+	Results  []UserSummary `json:"results,omitempty"`
+}
+
+// UserSummary models a single user within a UserList
+type UserSummary struct {
+	AuthType            string      `json:"auth_type,omitempty"`
+	IsDisabled          bool        `json:"is_disabled,omitempty"`
+	UpdatedAt           time.Time   `json:"updated_at,omitempty"`
+	ProfileImageURL     string      `json:"profile_image_url,omitempty"`
+	IsInvitationPending bool        `json:"is_invitation_pending,omitempty"`
+	Groups              []UserGroup `json:"groups,omitempty"`
+	ID                  int         `json:"id,omitempty"`
+	Name                string      `json:"name,omitempty"`
+	CreatedAt           time.Time   `json:"created_at,omitempty"`
+	DisabledAt          interface{} `json:"disabled_at,omitempty"`
+	IsEmailVerified     bool        `json:"is_email_verified,omitempty"`
+	ActiveAt            time.Time   `json:"active_at,omitempty"`
+	Email               string      `json:"email,omitempty"`
+}
+
+// UserGroup is a group reference embedded in a UserSummary
+type UserGroup struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// ToUser converts a list entry into a User, keeping only the group IDs
+func (u UserSummary) ToUser() *User {
+	var groups []int
+	for _, group := range u.Groups {
+		groups = append(groups, group.ID)
+	}
+
+	return &User{
+		AuthType:            u.AuthType,
+		IsDisabled:          u.IsDisabled,
+		UpdatedAt:           u.UpdatedAt,
+		ProfileImageURL:     u.ProfileImageURL,
+		IsInvitationPending: u.IsInvitationPending,
+		Groups:              groups,
+		ID:                  u.ID,
+		Name:                u.Name,
+		CreatedAt:           u.CreatedAt,
+		DisabledAt:          u.DisabledAt,
+		IsEmailVerified:     u.IsEmailVerified,
+		ActiveAt:            u.ActiveAt,
+		Email:               u.Email,
+	}
+}
+
+// UserListOptions selects the page of users returned by ListUsers
+type UserListOptions struct {
+	// Search matches the name and email fields
+	Search   string
+	Page     int
+	PageSize int
+}
+
+func (o *UserListOptions) values() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+
+	if o.Search != "" {
+		query.Add("q", o.Search)
+	}
+	setPageValues(query, o.Page, o.PageSize)
+
+	return query
 }
 
 // User representation
@@ -64,6 +116,31 @@ type User struct {
 	Email               string      `json:"email,omitempty"`
 }
 
+// Summary converts a User into the shape of a UserList entry. Group names are
+// not known to User, so only their IDs are set.
+func (u *User) Summary() UserSummary {
+	var groups []UserGroup
+	for _, id := range u.Groups {
+		groups = append(groups, UserGroup{ID: id})
+	}
+
+	return UserSummary{
+		AuthType:            u.AuthType,
+		IsDisabled:          u.IsDisabled,
+		UpdatedAt:           u.UpdatedAt,
+		ProfileImageURL:     u.ProfileImageURL,
+		IsInvitationPending: u.IsInvitationPending,
+		Groups:              groups,
+		ID:                  u.ID,
+		Name:                u.Name,
+		CreatedAt:           u.CreatedAt,
+		DisabledAt:          u.DisabledAt,
+		IsEmailVerified:     u.IsEmailVerified,
+		ActiveAt:            u.ActiveAt,
+		Email:               u.Email,
+	}
+}
+
 // UserCreatePayload struct for mutating users.
 type UserCreatePayload struct {
 	Name  string `json:"name"`
@@ -77,16 +154,32 @@ type UserUpdatePayload struct {
 	Groups []int  `json:"group_ids"`
 }
 
-//GetUsers returns a paginated list of users
+// GetUsers returns the first page of users, see UsersPager to iterate over all of them
 func (c *Client) GetUsers() (*UserList, error) {
+	return c.GetUsersContext(context.Background())
+}
+
+// GetUsersContext is like GetUsers but uses ctx for the underlying requests.
+func (c *Client) GetUsersContext(ctx context.Context) (*UserList, error) {
+	return c.ListUsersContext(ctx, nil)
+}
+
+// ListUsers returns a page of users selected by the options
+func (c *Client) ListUsers(options *UserListOptions) (*UserList, error) {
+	return c.ListUsersContext(context.Background(), options)
+}
+
+// ListUsersContext is like ListUsers but uses ctx for the underlying requests.
+func (c *Client) ListUsersContext(ctx context.Context, options *UserListOptions) (*UserList, error) {
 	path := "/api/users"
 
-	query := url.Values{}
-	response, err := c.get(path, query)
+	query := options.values()
+	response, err := c.get(ctx, path, query)
 
 	if err != nil {
 		return nil, err
 	}
+	defer response.Body.Close()
 	body, _ := ioutil.ReadAll(response.Body)
 
 	users := UserList{}
@@ -95,17 +188,53 @@ func (c *Client) GetUsers() (*UserList, error) {
 		return nil, err
 	}
 
-	defer response.Body.Close()
-
 	return &users, nil
 }
 
-//GetUser gets a specific User
+// UsersPager iterates over every user selected by the options
+func (c *Client) UsersPager(options *UserListOptions) *Pager[UserSummary] {
+	return c.UsersPagerContext(context.Background(), options)
+}
+
+// UsersPagerContext is like UsersPager but uses ctx for the underlying requests.
+func (c *Client) UsersPagerContext(ctx context.Context, options *UserListOptions) *Pager[UserSummary] {
+	pageOptions := UserListOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+
+	return NewPager(ctx, pageOptions.Page, pageOptions.PageSize, func(ctx context.Context, page int) ([]UserSummary, int, error) {
+		pageOptions.Page = page
+		users, err := c.ListUsersContext(ctx, &pageOptions)
+		if err != nil {
+			return nil, 0, err
+		}
+		return users.Results, users.Count, nil
+	})
+}
+
+// ListAllUsers returns every user selected by the options, up to limit users
+// when limit is positive
+func (c *Client) ListAllUsers(options *UserListOptions, limit int) ([]UserSummary, error) {
+	return c.ListAllUsersContext(context.Background(), options, limit)
+}
+
+// ListAllUsersContext is like ListAllUsers but uses ctx for the underlying requests.
+func (c *Client) ListAllUsersContext(ctx context.Context, options *UserListOptions, limit int) ([]UserSummary, error) {
+	return ListAll(c.UsersPagerContext(ctx, options), limit)
+}
+
+// GetUser gets a specific User
 func (c *Client) GetUser(id int) (*User, error) {
+	return c.GetUserContext(context.Background(), id)
+}
+
+// GetUserContext is like GetUser but uses ctx for the underlying requests.
+func (c *Client) GetUserContext(ctx context.Context, id int) (*User, error) {
 	path := "/api/users/" + strconv.Itoa(id)
 
 	query := url.Values{}
-	response, err := c.get(path, query)
+	response, err := c.get(ctx, path, query)
 	if err != nil {
 		return nil, err
 	}
@@ -126,8 +255,46 @@ func (c *Client) GetUser(id int) (*User, error) {
 	return &user, nil
 }
 
+// GetCurrentUser gets the user owning the API key
+func (c *Client) GetCurrentUser() (*User, error) {
+	return c.GetCurrentUserContext(context.Background())
+}
+
+// GetCurrentUserContext is like GetCurrentUser but uses ctx for the underlying requests.
+func (c *Client) GetCurrentUserContext(ctx context.Context) (*User, error) {
+	path := "/api/session"
+
+	query := url.Values{}
+	response, err := c.get(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	session := struct {
+		User User `json:"user"`
+	}{}
+
+	err = json.Unmarshal(body, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session.User, nil
+}
+
 // CreateUser creates a new Redash user
 func (c *Client) CreateUser(userCreatePayload *UserCreatePayload) (*User, error) {
+	return c.CreateUserContext(context.Background(), userCreatePayload)
+}
+
+// CreateUserContext is like CreateUser but uses ctx for the underlying requests.
+func (c *Client) CreateUserContext(ctx context.Context, userCreatePayload *UserCreatePayload) (*User, error) {
 	path := "/api/users"
 
 	payload, err := json.Marshal(userCreatePayload)
@@ -136,7 +303,7 @@ func (c *Client) CreateUser(userCreatePayload *UserCreatePayload) (*User, error)
 	}
 
 	query := url.Values{}
-	response, err := c.post(path, string(payload), query)
+	response, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +326,11 @@ func (c *Client) CreateUser(userCreatePayload *UserCreatePayload) (*User, error)
 
 // UpdateUser updates an existing Redash user
 func (c *Client) UpdateUser(id int, userUpdatePayload *UserUpdatePayload) (*User, error) {
+	return c.UpdateUserContext(context.Background(), id, userUpdatePayload)
+}
+
+// UpdateUserContext is like UpdateUser but uses ctx for the underlying requests.
+func (c *Client) UpdateUserContext(ctx context.Context, id int, userUpdatePayload *UserUpdatePayload) (*User, error) {
 	path := "/api/users/" + strconv.Itoa(id)
 
 	payload, err := json.Marshal(userUpdatePayload)
@@ -167,7 +339,7 @@ func (c *Client) UpdateUser(id int, userUpdatePayload *UserUpdatePayload) (*User
 	}
 
 	query := url.Values{}
-	response, err := c.post(path, string(payload), query)
+	response, err := c.post(ctx, path, string(payload), query)
 	if err != nil {
 		return nil, err
 	}
@@ -188,12 +360,17 @@ func (c *Client) UpdateUser(id int, userUpdatePayload *UserUpdatePayload) (*User
 	return &user, nil
 }
 
-//DisableUser disables an active user.
+// DisableUser disables an active user.
 func (c *Client) DisableUser(id int) error {
+	return c.DisableUserContext(context.Background(), id)
+}
+
+// DisableUserContext is like DisableUser but uses ctx for the underlying requests.
+func (c *Client) DisableUserContext(ctx context.Context, id int) error {
 	path := "/api/users/" + strconv.Itoa(id) + "/disable"
 
 	query := url.Values{}
-	response, err := c.post(path, "", query)
+	response, err := c.post(ctx, path, "", query)
 	if err != nil {
 		return err
 	}
@@ -207,43 +384,34 @@ func (c *Client) DisableUser(id int) error {
 	return nil
 }
 
-//SearchUsers finds a list of users matching a string (searches `name` and `email` fields)
+// SearchUsers finds a list of users matching a string (searches `name` and `email` fields)
 func (c *Client) SearchUsers(term string) (*UserList, error) {
-	path := "/api/users"
-
-	query := url.Values{}
-	query.Add("q", term)
-	response, err := c.get(path, query)
-
-	if err != nil {
-		return nil, err
-	}
-	body, _ := ioutil.ReadAll(response.Body)
-
-	users := UserList{}
-	err = json.Unmarshal(body, &users)
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
+	return c.SearchUsersContext(context.Background(), term)
+}
 
-	return &users, nil
+// SearchUsersContext is like SearchUsers but uses ctx for the underlying requests.
+func (c *Client) SearchUsersContext(ctx context.Context, term string) (*UserList, error) {
+	return c.ListUsersContext(ctx, &UserListOptions{Search: term})
 }
 
 // GetUserByEmail returns a single  user from their email address
 func (c *Client) GetUserByEmail(email string) (*User, error) {
+	return c.GetUserByEmailContext(context.Background(), email)
+}
+
+// GetUserByEmailContext is like GetUserByEmail but uses ctx for the underlying requests.
+func (c *Client) GetUserByEmailContext(ctx context.Context, email string) (*User, error) {
 
-	results, err := c.SearchUsers(email)
+	results, err := c.SearchUsersContext(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, result := range results.Results {
 		if result.Email != "" && result.Email == email {
-			return c.GetUser(result.ID)
+			return c.GetUserContext(ctx, result.ID)
 		}
 	}
 
-	return nil, fmt.Errorf("No user found with email address: %s", email)
+	return nil, newNotFoundError("No user found with email address: %s", email)
 }
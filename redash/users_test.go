@@ -109,6 +109,31 @@ func TestGetUserByEmail(t *testing.T) {
 	assert.NotNil(err)
 }
 
+func TestListAllUsers(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	c, _ := NewClient(&Config{RedashURI: "https://com.acme/", APIKey: "ApIkEyApIkEyApIkEyApIkEyApIkEy"})
+
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/users", "q=acme&page=1",
+		httpmock.NewStringResponder(200, `{"count": 3, "page": 1, "page_size": 2, "results": [{"id": 1, "email": "a@acme.com"}, {"id": 2, "email": "b@acme.com"}]}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://com.acme/api/users", "q=acme&page=2",
+		httpmock.NewStringResponder(200, `{"count": 3, "page": 2, "page_size": 2, "results": [{"id": 3, "email": "c@acme.com", "groups": [{"id": 1, "name": "admin"}]}]}`))
+
+	users, err := c.ListAllUsers(&UserListOptions{Search: "acme"}, 0)
+	assert.Nil(err)
+	assert.Equal(3, len(users))
+	assert.Equal("c@acme.com", users[2].Email)
+	assert.Equal("admin", users[2].Groups[0].Name)
+
+	user := users[2].ToUser()
+	assert.Equal(3, user.ID)
+	assert.Equal("c@acme.com", user.Email)
+	assert.Equal([]int{1}, user.Groups)
+	assert.Equal([]UserGroup{{ID: 1}}, user.Summary().Groups)
+}
+
 func TestDisableUser(t *testing.T) {
 	assert := assert.New(t)
 	httpmock.Activate()
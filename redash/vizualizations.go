@@ -1,8 +1,8 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/url"
 	"strconv"
 	"time"
@@ -73,7 +73,12 @@ type VisualizationUpdatePayload struct {
 
 // GetVisualization gets a specific visualization
 func (c *Client) GetVisualization(queryId, visualizationId int) (*Visualization, error) {
-	query, err := c.GetQuery(queryId)
+	return c.GetVisualizationContext(context.Background(), queryId, visualizationId)
+}
+
+// GetVisualizationContext is like GetVisualization but uses ctx for the underlying requests.
+func (c *Client) GetVisualizationContext(ctx context.Context, queryId, visualizationId int) (*Visualization, error) {
+	query, err := c.GetQueryContext(ctx, queryId)
 	if err != nil {
 		return nil, err
 	}
@@ -83,11 +88,16 @@ func (c *Client) GetVisualization(queryId, visualizationId int) (*Visualization,
 			return &v, nil
 		}
 	}
-	return nil, fmt.Errorf("visualization %d not found in query %d", visualizationId, queryId)
+	return nil, newNotFoundError("visualization %d not found in query %d", visualizationId, queryId)
 }
 
 // CreateVisualization creates a new Redash visualization
 func (c *Client) CreateVisualization(visualizationCreatePayload *VisualizationCreatePayload) (*Visualization, error) {
+	return c.CreateVisualizationContext(context.Background(), visualizationCreatePayload)
+}
+
+// CreateVisualizationContext is like CreateVisualization but uses ctx for the underlying requests.
+func (c *Client) CreateVisualizationContext(ctx context.Context, visualizationCreatePayload *VisualizationCreatePayload) (*Visualization, error) {
 	path := "/api/visualizations"
 
 	payload, err := json.Marshal(visualizationCreatePayload)
@@ -95,7 +105,7 @@ func (c *Client) CreateVisualization(visualizationCreatePayload *VisualizationCr
 		return nil, err
 	}
 
-	response, err := c.post(path, string(payload), url.Values{})
+	response, err := c.post(ctx, path, string(payload), url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -112,6 +122,11 @@ func (c *Client) CreateVisualization(visualizationCreatePayload *VisualizationCr
 
 // UpdateVisualization updates an existing Redash visualization
 func (c *Client) UpdateVisualization(id int, visualizationUpdatePayload *VisualizationUpdatePayload) (*Visualization, error) {
+	return c.UpdateVisualizationContext(context.Background(), id, visualizationUpdatePayload)
+}
+
+// UpdateVisualizationContext is like UpdateVisualization but uses ctx for the underlying requests.
+func (c *Client) UpdateVisualizationContext(ctx context.Context, id int, visualizationUpdatePayload *VisualizationUpdatePayload) (*Visualization, error) {
 	path := "/api/visualizations/" + strconv.Itoa(id)
 
 	payload, err := json.Marshal(visualizationUpdatePayload)
@@ -119,7 +134,7 @@ func (c *Client) UpdateVisualization(id int, visualizationUpdatePayload *Visuali
 		return nil, err
 	}
 
-	response, err := c.post(path, string(payload), url.Values{})
+	response, err := c.post(ctx, path, string(payload), url.Values{})
 	if err != nil {
 		return nil, err
 	}
@@ -136,9 +151,18 @@ func (c *Client) UpdateVisualization(id int, visualizationUpdatePayload *Visuali
 
 // DeleteVisualization deletes a visualization
 func (c *Client) DeleteVisualization(id int) error {
+	return c.DeleteVisualizationContext(context.Background(), id)
+}
+
+// DeleteVisualizationContext is like DeleteVisualization but uses ctx for the underlying requests.
+func (c *Client) DeleteVisualizationContext(ctx context.Context, id int) error {
 	path := "/api/visualizations/" + strconv.Itoa(id)
 
-	_, err := c.delete(path, url.Values{})
+	response, err := c.delete(ctx, path, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
 
-	return err
+	return nil
 }
@@ -1,8 +1,8 @@
 package redash
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/url"
 	"strconv"
 	"time"
@@ -61,7 +61,12 @@ type WidgetUpdatePayload struct {
 
 // GetWidget returns a specific Widget
 func (c *Client) GetWidget(dashboardSlug string, widgetId int) (*Widget, error) {
-	dashboard, err := c.GetDashboard(dashboardSlug)
+	return c.GetWidgetContext(context.Background(), dashboardSlug, widgetId)
+}
+
+// GetWidgetContext is like GetWidget but uses ctx for the underlying requests.
+func (c *Client) GetWidgetContext(ctx context.Context, dashboardSlug string, widgetId int) (*Widget, error) {
+	dashboard, err := c.GetDashboardContext(ctx, dashboardSlug)
 	if err != nil {
 		return nil, err
 	}
@@ -72,10 +77,15 @@ func (c *Client) GetWidget(dashboardSlug string, widgetId int) (*Widget, error)
 		}
 	}
 
-	return nil, fmt.Errorf("widget %d not found in dashboard %s", widgetId, dashboardSlug)
+	return nil, newNotFoundError("widget %d not found in dashboard %s", widgetId, dashboardSlug)
 }
 
 func (c *Client) CreateWidget(widgetCreatePayload *WidgetCreatePayload) (*Widget, error) {
+	return c.CreateWidgetContext(context.Background(), widgetCreatePayload)
+}
+
+// CreateWidgetContext is like CreateWidget but uses ctx for the underlying requests.
+func (c *Client) CreateWidgetContext(ctx context.Context, widgetCreatePayload *WidgetCreatePayload) (*Widget, error) {
 	path := "/api/widgets"
 
 	payload, err := json.Marshal(widgetCreatePayload)
@@ -84,7 +94,7 @@ func (c *Client) CreateWidget(widgetCreatePayload *WidgetCreatePayload) (*Widget
 	}
 
 	queryParams := url.Values{}
-	response, err := c.post(path, string(payload), queryParams)
+	response, err := c.post(ctx, path, string(payload), queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +110,11 @@ func (c *Client) CreateWidget(widgetCreatePayload *WidgetCreatePayload) (*Widget
 }
 
 func (c *Client) UpdateWidget(id int, widgetUpdatePayload *WidgetUpdatePayload) (*Widget, error) {
+	return c.UpdateWidgetContext(context.Background(), id, widgetUpdatePayload)
+}
+
+// UpdateWidgetContext is like UpdateWidget but uses ctx for the underlying requests.
+func (c *Client) UpdateWidgetContext(ctx context.Context, id int, widgetUpdatePayload *WidgetUpdatePayload) (*Widget, error) {
 	path := "/api/widgets/" + strconv.Itoa(id)
 
 	payload, err := json.Marshal(widgetUpdatePayload)
@@ -108,7 +123,7 @@ func (c *Client) UpdateWidget(id int, widgetUpdatePayload *WidgetUpdatePayload)
 	}
 
 	queryParams := url.Values{}
-	response, err := c.post(path, string(payload), queryParams)
+	response, err := c.post(ctx, path, string(payload), queryParams)
 	if err != nil {
 		return nil, err
 	}
@@ -124,9 +139,18 @@ func (c *Client) UpdateWidget(id int, widgetUpdatePayload *WidgetUpdatePayload)
 }
 
 func (c *Client) DeleteWidget(id int) error {
+	return c.DeleteWidgetContext(context.Background(), id)
+}
+
+// DeleteWidgetContext is like DeleteWidget but uses ctx for the underlying requests.
+func (c *Client) DeleteWidgetContext(ctx context.Context, id int) error {
 	path := "/api/widgets/" + strconv.Itoa(id)
 
-	_, err := c.delete(path, url.Values{})
+	response, err := c.delete(ctx, path, url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
 
-	return err
+	return nil
 }
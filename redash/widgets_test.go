@@ -31,6 +31,11 @@ func TestGetWidget(t *testing.T) {
 
 	assert.NotNil(widget.Options)
 	assert.Equal(234610, widget.Visualization.ID)
+
+	widget, err = c.GetWidget("service-slos", 1)
+	assert.Nil(widget)
+	assert.True(IsNotFound(err))
+	assert.Equal("widget 1 not found in dashboard service-slos", err.Error())
 }
 
 func TestCreateWidget(t *testing.T) {